@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Luc1808/TaskAPI/internal/api"
+	"github.com/Luc1808/TaskAPI/internal/api/middleware"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/blob"
+	"github.com/Luc1808/TaskAPI/internal/jobs"
 	"github.com/Luc1808/TaskAPI/internal/repository"
 	"github.com/Luc1808/TaskAPI/internal/repository/postgres"
 	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
 	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 )
@@ -32,11 +41,214 @@ func main() {
 	db := sqlx.NewDb(rawDb, "pgx")
 
 	taskRepo := postgres.NewTaskRepo(db)
-	taskSvc := service.NewTaskService(taskRepo)
-	r := api.NewRouter(taskSvc)
+	statusRepo := postgres.NewStatusRepo(db)
+	taskSvc := service.NewTaskService(taskRepo,
+		service.WithCursorSecret([]byte(os.Getenv("CURSOR_SECRET"))),
+		service.WithChildPolicy(service.ChildPolicy(os.Getenv("DELETE_CHILDREN_POLICY"))),
+		service.WithStatusCatalog(statusRepo),
+		service.WithAccessControl(postgres.NewTaskMemberRepo(db)),
+	)
+	idempotencyTTL := envDuration("IDEMPOTENCY_TTL", middleware.DefaultIdempotencyTTL)
+	trashRetention := envDuration("TRASH_RETENTION", service.DefaultTrashRetention)
+	// Auto-archiving closed tasks is off unless AUTO_ARCHIVE_AFTER is set
+	autoArchiveAfter := envDuration("AUTO_ARCHIVE_AFTER", 0)
+	// 0 leaves comments editable forever
+	commentEditWindow := envDuration("COMMENT_EDIT_WINDOW", service.DefaultCommentEditWindow)
+
+	idempotencyRepo := postgres.NewIdempotencyRepo(db)
+	maxAttachment := envBytes("ATTACHMENT_MAX_BYTES", service.DefaultMaxAttachmentSize)
+	attachmentSvc := service.NewAttachmentService(postgres.NewAttachmentRepo(db), taskSvc, blobStore(),
+		service.AttachmentLimits{
+			MaxSize:      maxAttachment,
+			AllowedTypes: splitEnv("ATTACHMENT_TYPES"),
+		})
+
+	userSvc := service.NewUserService(postgres.NewUserRepo(db), resetSender())
+	tokenSvc := service.NewTokenService(userSvc, postgres.NewSessionRepo(db), keySet(), service.TokenOptions{
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		AccessTTL:  envDuration("ACCESS_TOKEN_TTL", service.DefaultAccessTokenTTL),
+		RefreshTTL: envDuration("REFRESH_TOKEN_TTL", service.DefaultRefreshTokenTTL),
+	})
+
+	workspaceSvc := service.NewWorkspaceService(postgres.NewWorkspaceRepo(db))
+
+	// Tasks sit behind row-level security, so jobs over them run once per workspace
+	ctx := context.Background()
+	jobs.Every(ctx, "purge-idempotency-keys", time.Hour, func(ctx context.Context) error {
+		_, err := idempotencyRepo.DeleteExpired(ctx)
+		return err
+	})
+	jobs.Every(ctx, "purge-sessions", time.Hour, func(ctx context.Context) error {
+		_, err := tokenSvc.PurgeSessions(ctx)
+		return err
+	})
+	jobs.Every(ctx, "purge-trash", time.Hour, func(ctx context.Context) error {
+		return workspaceSvc.Each(ctx, func(ctx context.Context) error {
+			n, err := taskSvc.PurgeTrash(ctx, trashRetention)
+			if n > 0 {
+				log.Printf("purged %d trashed tasks in workspace %s", n, tenant.WorkspaceID(ctx))
+			}
+			return err
+		})
+	})
+	jobs.Every(ctx, "sweep-orphaned-blobs", time.Hour, func(ctx context.Context) error {
+		n, err := attachmentSvc.SweepOrphanedBlobs(ctx)
+		if n > 0 {
+			log.Printf("removed %d orphaned attachment blobs", n)
+		}
+		return err
+	})
+	if autoArchiveAfter > 0 {
+		jobs.Every(ctx, "auto-archive", time.Hour, func(ctx context.Context) error {
+			return workspaceSvc.Each(ctx, func(ctx context.Context) error {
+				n, err := taskSvc.AutoArchive(ctx, autoArchiveAfter)
+				if n > 0 {
+					log.Printf("archived %d closed tasks in workspace %s", n, tenant.WorkspaceID(ctx))
+				}
+				return err
+			})
+		})
+	}
+
+	r := api.NewRouter(api.Services{
+		Tasks:        taskSvc,
+		Tags:         service.NewTagService(postgres.NewTagRepo(db), taskSvc),
+		Dependencies: service.NewDependencyService(postgres.NewDependencyRepo(db), taskSvc),
+		Statuses:     service.NewStatusService(statusRepo, workspaceSvc),
+		History:      service.NewHistoryService(postgres.NewTaskEventRepo(db), taskSvc),
+		Comments:     service.NewCommentService(postgres.NewCommentRepo(db), taskSvc, commentEditWindow),
+		Attachments:  attachmentSvc,
+		Users:        userSvc,
+		Tokens:       tokenSvc,
+		APIKeys:      service.NewAPIKeyService(postgres.NewAPIKeyRepo(db)),
+		Workspaces:   workspaceSvc,
+
+		WorkspaceDomain: os.Getenv("WORKSPACE_DOMAIN"),
+
+		Idempotency:    idempotencyRepo,
+		IdempotencyTTL: idempotencyTTL,
+		// uploads need room for the file plus its multipart envelope
+		IdempotencyMaxBody: maxAttachment + middleware.DefaultIdempotencyMaxBody,
+	})
 
 	log.Printf("server starting on :%s", port)
 	if err := http.ListenAndServe(":"+port, r); err != nil {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// envDuration reads a Go duration such as "24h" from the environment.
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Fatalf("invalid %s: %q", name, v)
+	}
+	return d
+}
+
+// envBytes reads a positive byte count from the environment.
+func envBytes(name string, def int64) int64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid %s: %q", name, v)
+	}
+	return n
+}
+
+// splitEnv reads a comma-separated list; nil when unset.
+func splitEnv(name string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// resetSender delivers password reset tokens. There is no mail integration
+// yet; PASSWORD_RESET_LOG=true logs tokens for local development.
+func resetSender() service.ResetSender {
+	if os.Getenv("PASSWORD_RESET_LOG") == "true" {
+		return service.LogResetSender{}
+	}
+	return nil
+}
+
+// keySet loads the keys access tokens are signed with. JWT_KEYS_FILE names
+// a JWK Set (HS256, RS256 or EdDSA keys) and JWT_SIGNING_KEY_ID the key to
+// sign with; verification accepts any key in the set, so keys can be
+// rotated. JWT_SECRET is a shortcut for a single HS256 key. With neither,
+// a random key is used and tokens do not survive a restart.
+func keySet() *auth.KeySet {
+	var keys []*auth.Key
+	switch {
+	case os.Getenv("JWT_KEYS_FILE") != "":
+		data, err := os.ReadFile(os.Getenv("JWT_KEYS_FILE"))
+		if err != nil {
+			log.Fatalf("jwt keys: %v", err)
+		}
+		if keys, err = auth.ParseJWKS(data); err != nil {
+			log.Fatalf("jwt keys: %v", err)
+		}
+	case os.Getenv("JWT_SECRET") != "":
+		k, err := auth.NewHMACKey("default", []byte(os.Getenv("JWT_SECRET")))
+		if err != nil {
+			log.Fatalf("jwt keys: %v", err)
+		}
+		keys = append(keys, k)
+	default:
+		log.Println("JWT_KEYS_FILE and JWT_SECRET are unset; signing tokens with a temporary key")
+		k, err := auth.GenerateHMACKey("ephemeral")
+		if err != nil {
+			log.Fatalf("jwt keys: %v", err)
+		}
+		keys = append(keys, k)
+	}
+
+	ks, err := auth.NewKeySet(os.Getenv("JWT_SIGNING_KEY_ID"), keys...)
+	if err != nil {
+		log.Fatalf("jwt keys: %v", err)
+	}
+	return ks
+}
+
+// blobStore picks where attachment bytes live: ATTACHMENT_STORE=local
+// (the default, under ATTACHMENT_DIR) or s3.
+func blobStore() repository.BlobStore {
+	switch store := os.Getenv("ATTACHMENT_STORE"); store {
+	case "", "local":
+		dir := os.Getenv("ATTACHMENT_DIR")
+		if dir == "" {
+			dir = "data/attachments"
+		}
+		s, err := blob.NewLocalStore(dir)
+		if err != nil {
+			log.Fatalf("attachment store: %v", err)
+		}
+		return s
+	case "s3":
+		s, err := blob.NewS3Store(blob.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+		if err != nil {
+			log.Fatalf("attachment store: %v", err)
+		}
+		return s
+	default:
+		log.Fatalf("invalid ATTACHMENT_STORE: %q", store)
+		return nil
+	}
+}
@@ -40,6 +40,7 @@ func main() {
 		Title:       "Phase 4 – wire repo",
 		Description: "Implement domain + repo + adapter",
 		Status:      models.StatusTodo,
+		Priority:    models.PriorityDefault,
 		DueAt:       &due,
 	}
 	created, err := repo.Create(ctx, t)
@@ -73,7 +74,7 @@ func main() {
 	fmt.Println("List count:", len(list))
 
 	// 5) Delete
-	if err := repo.Delete(ctx, created.ID); err != nil {
+	if err := repo.Delete(ctx, created.ID, 0); err != nil {
 		log.Fatal("delete:", err)
 	}
 	fmt.Println("Deleted:", created.ID)
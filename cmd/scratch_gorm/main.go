@@ -43,6 +43,7 @@ func main() {
 		Title:       "GORM adapter test",
 		Description: "Create → Read → Update → Delete",
 		Status:      models.StatusTodo,
+		Priority:    models.PriorityDefault,
 		DueAt:       &due,
 	}
 	created, err := repo.Create(ctx, t)
@@ -74,7 +75,7 @@ func main() {
 	fmt.Println("List count:", len(list))
 
 	// Delete
-	if err := repo.Delete(ctx, created.ID); err != nil {
+	if err := repo.Delete(ctx, created.ID, 0); err != nil {
 		log.Fatal("delete:", err)
 	}
 	fmt.Println("Deleted:", created.ID)
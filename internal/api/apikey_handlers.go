@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type APIKeyHandler struct {
+	svc *service.APIKeyService
+}
+
+func NewAPIKeyHandler(svc *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey returns the key itself; it can't be retrieved again.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req service.CreateAPIKeyInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	key, err := h.svc.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.RevokeAPIKey(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartOverhead leaves room for boundaries and part headers on top of
+// the file itself.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	svc *service.AttachmentService
+}
+
+func NewAttachmentHandler(svc *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+func (h *AttachmentHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	attachments, err := h.svc.ListAttachments(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, attachments)
+}
+
+// UploadAttachment streams the "file" part of a multipart form to the
+// service; other parts are ignored.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.svc.MaxSize()+multipartOverhead)
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, service.ErrInvalidUpload)
+		return
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, service.ErrAttachmentTooLarge)
+				return
+			}
+			writeError(w, service.ErrInvalidUpload)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := h.svc.Upload(r.Context(), chi.URLParam(r, "id"), service.UploadInput{
+			Filename: part.FileName(),
+			Body:     part,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	attachment, body, err := h.svc.Open(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "attachmentID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, body)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteAttachment(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "attachmentID")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type CommentHandler struct {
+	svc *service.CommentService
+}
+
+func NewCommentHandler(svc *service.CommentService) *CommentHandler {
+	return &CommentHandler{svc: svc}
+}
+
+func (h *CommentHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := h.svc.ListComments(r.Context(), chi.URLParam(r, "id"), q.Get("page"), q.Get("page_size"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	meta := listMeta{
+		Total:    page.Total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+		HasMore:  page.HasMore,
+	}
+	writePage(w, r, http.StatusOK, page.Comments, meta, "", "")
+}
+
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	var req service.CommentInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	comment, err := h.svc.CreateComment(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	var req service.CommentInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	comment, err := h.svc.UpdateComment(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "commentID"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comment)
+}
+
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.DeleteComment(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "commentID")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type DependencyHandler struct {
+	svc *service.DependencyService
+}
+
+func NewDependencyHandler(svc *service.DependencyService) *DependencyHandler {
+	return &DependencyHandler{svc: svc}
+}
+
+func (h *DependencyHandler) ListBlockers(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	result, err := h.svc.ListBlockers(r.Context(), id, listOptions(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, r, http.StatusOK, result)
+}
+
+func (h *DependencyHandler) AddBlocker(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	blockerID := chi.URLParam(r, "blockerID")
+
+	task, err := h.svc.AddBlocker(r.Context(), id, blockerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *DependencyHandler) RemoveBlocker(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	blockerID := chi.URLParam(r, "blockerID")
+
+	task, err := h.svc.RemoveBlocker(r.Context(), id, blockerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+)
+
+// setETag exposes a task version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch reads the If-Match header. A missing header or "*" matches any
+// version; weak or malformed tags never match (RFC 9110 strong comparison).
+func ifMatch(r *http.Request) service.VersionMatch {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := service.VersionMatch{}
+	for _, tag := range splitList(header) {
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil || !strings.HasPrefix(tag, `"`) {
+			continue
+		}
+		if v, err := strconv.Atoi(unquoted); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
@@ -1,10 +1,16 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/Luc1808/TaskAPI/pkg/models"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -28,21 +34,157 @@ func (h *TaskHandler) HealthHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.ListTasks(r.Context(), listOptions(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, r, http.StatusOK, result)
+}
+
+func (h *TaskHandler) ListChildren(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	result, err := h.svc.ListChildren(r.Context(), id, listOptions(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, r, http.StatusOK, result)
+}
+
+func (h *TaskHandler) GetSubtree(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	tree, err := h.svc.GetSubtree(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (h *TaskHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.ListTrash(r.Context(), listOptions(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeList(w, r, http.StatusOK, result)
+}
+
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	task, err := h.svc.RestoreTask(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.svc.ArchiveTask)
+}
+
+func (h *TaskHandler) UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, h.svc.UnarchiveTask)
+}
+
+func (h *TaskHandler) setArchived(w http.ResponseWriter, r *http.Request,
+	apply func(ctx context.Context, id string, ifMatch service.VersionMatch) (models.Task, error)) {
+	id := chi.URLParam(r, "id")
+
+	task, err := apply(r.Context(), id, ifMatch(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, task.Version)
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *TaskHandler) PurgeTask(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.svc.PurgeTask(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.svc.EmptyTrash(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listOptions reads the GET /tasks query string.
+func listOptions(r *http.Request) service.ListOptions {
 	status := r.URL.Query().Get("status")
+	priority := r.URL.Query().Get("priority")
+	sort := splitList(r.URL.Query().Get("sort"))
+	dueBefore := r.URL.Query().Get("due_before")
+	dueAfter := r.URL.Query().Get("due_after")
+	overdue := r.URL.Query().Get("overdue")
+	cursor := r.URL.Query().Get("cursor")
+	tags := splitList(r.URL.Query().Get("tag"))
+	tagsAny := splitList(r.URL.Query().Get("tag_any"))
+	tagsNone := splitList(r.URL.Query().Get("tag_none"))
+	parentID := r.URL.Query().Get("parent_id")
+	archived := r.URL.Query().Get("archived")
+	includeArchived := r.URL.Query().Get("include_archived")
+	search := r.URL.Query().Get("q")
+	if search == "" {
+		search = r.URL.Query().Get("search")
+	}
 
 	pageStr := r.URL.Query().Get("page")
 	sizeStr := r.URL.Query().Get("page_size")
 
-	result, err := h.svc.ListTasks(r.Context(), service.ListOptions{
-		Status:   status,
-		Page:     pageStr,
-		PageSize: sizeStr,
-	})
-	if err != nil {
-		writeError(w, err)
+	return service.ListOptions{
+		ParentID:  parentID,
+		Status:    status,
+		Priority:  priority,
+		DueBefore: dueBefore,
+		DueAfter:  dueAfter,
+		Overdue:   overdue,
+		Tags:      tags,
+		TagsAny:   tagsAny,
+		TagsNone:  tagsNone,
+		Search:    search,
+		Sort:      sort,
+		Cursor:    cursor,
+		Page:      pageStr,
+		PageSize:  sizeStr,
+
+		Archived:        archived,
+		IncludeArchived: includeArchived,
 	}
+}
 
-	writeJSON(w, http.StatusOK, result)
+// splitList turns "a, b,c" into [a b c], dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for part := range strings.SplitSeq(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
 }
 
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -54,6 +196,7 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, task.Version)
 	writeJSON(w, http.StatusOK, task)
 }
 
@@ -68,44 +211,130 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      req.Status,
+		Priority:    req.Priority,
+		DueAt:       req.DueAt,
+		ParentID:    req.ParentID,
 	})
 	if err != nil {
 		writeError(w, err)
 		return
 	}
 
+	setETag(w, newTask.Version)
 	writeJSON(w, http.StatusCreated, newTask)
 }
 
+// UpdateTask handles PUT, a full replacement of the task.
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	var req service.UpdateTaskInput
+	var req service.ReplaceTaskInput
 	if err := decodeJSON(w, r, &req); err != nil {
 		writeError(w, err)
 		return
 	}
+	req.IfMatch = ifMatch(r)
 
-	updated, err := h.svc.UpdateTask(r.Context(), id, service.UpdateTaskInput{
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      req.Status,
+	updated, err := h.svc.ReplaceTask(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	setETag(w, updated.Version)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1_000_000))
+	if err != nil {
+		writeError(w, service.WrapValidation(errors.New("body is too large")))
+		return
+	}
+
+	updated, err := h.svc.PatchTask(r.Context(), id, service.PatchInput{
+		ContentType:    mediaType,
+		Body:           body,
+		IgnoreBlockers: r.URL.Query().Get("ignore_blockers") == "true",
+		Reopen:         r.URL.Query().Get("reopen") == "true",
+		IfMatch:        ifMatch(r),
 	})
 	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedPatch) {
+			w.Header().Set("Accept-Patch", service.MergePatchType+", "+service.JSONPatchType)
+		}
 		writeError(w, err)
 		return
 	}
 
+	setETag(w, updated.Version)
 	writeJSON(w, http.StatusOK, updated)
 }
 
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	if err := h.svc.DeleteTask(r.Context(), id); err != nil {
+	opts := service.DeleteOptions{
+		Children: service.ChildPolicy(r.URL.Query().Get("children")),
+		IfMatch:  ifMatch(r),
+	}
+
+	if err := h.svc.DeleteTask(r.Context(), id, opts); err != nil {
 		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+type batchRequest struct {
+	Mode       service.BatchMode        `json:"mode"`
+	Operations []service.BatchOperation `json:"operations"`
+}
+
+type batchResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	Status int          `json:"status"`
+	Data   *models.Task `json:"data"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// BatchTasks handles POST /tasks:batch. An atomic batch that fails answers
+// with the failing operation's error; otherwise every operation gets a result.
+func (h *TaskHandler) BatchTasks(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	results, err := h.svc.Batch(r.Context(), service.BatchInput{
+		Mode:       req.Mode,
+		Operations: req.Operations,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]batchResult, len(results))
+	for i, res := range results {
+		out[i] = batchResult{Index: i, Op: string(res.Op), Data: res.Task}
+		switch {
+		case res.Err != nil:
+			out[i].Status, out[i].Error = errorStatus(res.Err)
+		case res.Op == service.BatchCreate:
+			out[i].Status = http.StatusCreated
+		case res.Op == service.BatchDelete:
+			out[i].Status = http.StatusNoContent
+		default:
+			out[i].Status = http.StatusOK
+		}
+	}
+
+	writeJSON(w, http.StatusOK, out)
+}
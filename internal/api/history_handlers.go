@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type HistoryHandler struct {
+	svc *service.HistoryService
+}
+
+func NewHistoryHandler(svc *service.HistoryService) *HistoryHandler {
+	return &HistoryHandler{svc: svc}
+}
+
+func (h *HistoryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := h.svc.History(r.Context(), chi.URLParam(r, "id"), q.Get("page"), q.Get("page_size"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	meta := listMeta{
+		Total:    page.Total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+		HasMore:  page.HasMore,
+	}
+	writePage(w, r, http.StatusOK, page.Events, meta, "", "")
+}
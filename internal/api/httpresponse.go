@@ -3,14 +3,27 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/Luc1808/TaskAPI/internal/service"
 )
 
 type envelope struct {
-	Data  any    `json:"data"`
-	Error string `json:"error"`
+	Data       any       `json:"data"`
+	Error      string    `json:"error"`
+	Meta       *listMeta `json:"meta,omitempty"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+}
+
+type listMeta struct {
+	Total    int  `json:"total"`
+	Page     int  `json:"page,omitempty"`
+	PageSize int  `json:"page_size"`
+	HasMore  bool `json:"has_more"`
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -25,18 +38,151 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	_ = json.NewEncoder(w).Encode(res)
 }
 
-func writeError(w http.ResponseWriter, err error) {
+func writeList(w http.ResponseWriter, r *http.Request, status int, page *service.TaskPage) {
+	meta := listMeta{
+		Total:    page.Total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+		HasMore:  page.HasMore,
+	}
+	writePage(w, r, status, page.Tasks, meta, page.NextCursor, page.PrevCursor)
+}
+
+// writePage writes one page of any listing with its meta and Link header.
+func writePage(w http.ResponseWriter, r *http.Request, status int, data any, meta listMeta, next, prev string) {
+	setLinkHeader(w, r, meta, next, prev)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	res := envelope{
+		Data:       data,
+		Error:      "",
+		Meta:       &meta,
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// setLinkHeader advertises neighbouring pages per RFC 8288. Offset pages
+// link by page number, cursor pages by their cursor tokens.
+func setLinkHeader(w http.ResponseWriter, r *http.Request, page listMeta, next, prev string) {
+	link := func(rel string, set map[string]string) string {
+		u := *r.URL
+		q := u.Query()
+		for k, v := range set {
+			if v == "" {
+				q.Del(k)
+			} else {
+				q.Set(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel)
+	}
+
+	var links []string
+	if page.Page > 0 {
+		size := strconv.Itoa(page.PageSize)
+		lastPage := max(1, (page.Total+page.PageSize-1)/page.PageSize)
+
+		links = append(links, link("first", map[string]string{"page": "1", "page_size": size}))
+		if page.Page > 1 {
+			links = append(links, link("prev", map[string]string{"page": strconv.Itoa(page.Page - 1), "page_size": size}))
+		}
+		if page.HasMore {
+			links = append(links, link("next", map[string]string{"page": strconv.Itoa(page.Page + 1), "page_size": size}))
+		}
+		links = append(links, link("last", map[string]string{"page": strconv.Itoa(lastPage), "page_size": size}))
+	} else {
+		if prev != "" {
+			links = append(links, link("prev", map[string]string{"cursor": prev, "page": ""}))
+		}
+		if next != "" {
+			links = append(links, link("next", map[string]string{"cursor": next, "page": ""}))
+		}
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// Service errors whose message is safe to show, grouped by HTTP status
+var errorStatuses = []struct {
+	status int
+	errs   []error
+}{
+	{http.StatusBadRequest, []error{
+		service.ErrValidation,
+		service.ErrInvalidStatus, service.ErrInvalidTitle, service.ErrInvalidPriority,
+		service.ErrInvalidSort, service.ErrInvalidDueAt, service.ErrInvalidFilter,
+		service.ErrInvalidCursor, service.ErrInvalidSearch, service.ErrInvalidPage, service.ErrInvalidTagName,
+		service.ErrInvalidParent, service.ErrParentCycle, service.ErrTreeTooDeep,
+		service.ErrInvalidCascade, service.ErrDependencyCycle,
+		service.ErrInvalidStatusName, service.ErrInvalidStatusCategory, service.ErrInvalidStatusColor,
+		service.ErrInvalidPatch, service.ErrInvalidBatch, service.ErrInvalidComment,
+		service.ErrInvalidUpload, service.ErrInvalidEmail, service.ErrInvalidPassword,
+		service.ErrInvalidUserName, service.ErrInvalidResetToken,
+		service.ErrInvalidAPIKeyName, service.ErrInvalidScope, service.ErrInvalidExpiry,
+		service.ErrInvalidRole, service.ErrNoWorkspace, service.ErrInvalidWorkspaceSlug,
+		service.ErrInvalidWorkspaceName, service.ErrInvalidWorkspaceRole,
+	}},
+	{http.StatusUnauthorized, []error{
+		service.ErrInvalidCredentials, service.ErrUnauthenticated,
+		service.ErrInvalidToken, service.ErrInvalidRefreshToken, service.ErrInvalidAPIKey,
+	}},
+	{http.StatusForbidden, []error{
+		service.ErrSessionRequired, service.ErrForbidden, service.ErrWrongWorkspace,
+	}},
+	{http.StatusNotFound, []error{
+		service.ErrNotFound, service.ErrTagNotFound, service.ErrDependencyNotFound,
+		service.ErrStatusNotFound, service.ErrCommentNotFound, service.ErrAttachmentNotFound,
+		service.ErrUserNotFound, service.ErrAPIKeyNotFound, service.ErrMemberNotFound,
+		service.ErrWorkspaceNotFound,
+	}},
+	{http.StatusConflict, []error{
+		service.ErrTagExists, service.ErrHasChildren, service.ErrBlocked,
+		service.ErrInvalidTransition, service.ErrStatusExists, service.ErrStatusInUse,
+		service.ErrPatchTestFailed, service.ErrEditWindowExpired, service.ErrEmailTaken,
+		service.ErrLastOwner, service.ErrSlugTaken, service.ErrLastAdmin,
+		service.ErrDependencyGraphTooLarge,
+	}},
+	{http.StatusPreconditionFailed, []error{
+		service.ErrPreconditionFailed,
+	}},
+	{http.StatusRequestEntityTooLarge, []error{
+		service.ErrAttachmentTooLarge,
+	}},
+	{http.StatusUnsupportedMediaType, []error{
+		service.ErrUnsupportedPatch, service.ErrAttachmentType,
+	}},
+}
+
+// errorStatus picks the HTTP status for err and the message safe to show.
+func errorStatus(err error) (int, string) {
 	status := http.StatusInternalServerError
 	msg := "internal error"
 
-	if (errors.Is(err, service.ErrInvalidStatus)) || (errors.Is(err, service.ErrInvalidTitle)) {
-		status = http.StatusBadRequest
-		msg = err.Error()
-	} else if errors.Is(err, service.ErrNotFound) {
-		status = http.StatusNotFound
-		msg = err.Error()
+	for _, group := range errorStatuses {
+		for _, target := range group.errs {
+			if errors.Is(err, target) {
+				status = group.status
+				msg = err.Error()
+			}
+		}
 	}
 
+	return status, msg
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, msg := errorStatus(err)
+
+	if errors.Is(err, service.ErrUnauthenticated) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
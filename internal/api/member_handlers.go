@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type MemberHandler struct {
+	svc *service.TaskService
+}
+
+func NewMemberHandler(svc *service.TaskService) *MemberHandler {
+	return &MemberHandler{svc: svc}
+}
+
+func (h *MemberHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := h.svc.ListMembers(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (h *MemberHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	var req service.MemberInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	member, err := h.svc.SetMember(r.Context(), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+func (h *MemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.RemoveMember(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "userID")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
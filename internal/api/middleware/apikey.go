@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/service"
+)
+
+// APIKeyVerifier resolves an API key to the principal it acts for.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// APIKeys authenticates requests carrying an API key, either in X-API-Key
+// or as a bearer token starting with auth.APIKeyPrefix. Other requests are
+// left to Authenticate, which must come after it.
+func APIKeys(v APIKeyVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("X-API-Key")
+			if key == "" {
+				scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+				if strings.EqualFold(scheme, "Bearer") && strings.HasPrefix(strings.TrimSpace(token), auth.APIKeyPrefix) {
+					key = strings.TrimSpace(token)
+				}
+			}
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p, err := v.VerifyAPIKey(r.Context(), key)
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err != nil {
+				log.Printf("auth: verify api key: %v", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), p)
+			ctx = audit.WithActor(ctx, p.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope limits API keys to the routes their scopes cover: safe
+// methods need read, everything else write. Anonymous requests and login
+// sessions pass through.
+func RequireScope(read, write string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := write
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = read
+			}
+
+			if p := auth.FromContext(r.Context()); p != nil && !p.Allows(scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				writeError(w, http.StatusForbidden, "api key lacks the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+)
+
+func TestAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		key     string
+	}{
+		{name: "no key", status: http.StatusNoContent},
+		{name: "X-API-Key", headers: map[string]string{"X-API-Key": "tapi_good"}, status: http.StatusNoContent, key: "k1"},
+		{name: "bearer key", headers: map[string]string{"Authorization": "Bearer tapi_good"}, status: http.StatusNoContent, key: "k1"},
+		{name: "bearer token is left to Authenticate", headers: map[string]string{"Authorization": "Bearer good"}, status: http.StatusNoContent},
+		{name: "invalid key", headers: map[string]string{"X-API-Key": "tapi_nope"}, status: http.StatusUnauthorized},
+		{name: "verifier failure", headers: map[string]string{"X-API-Key": "tapi_boom"}, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			var got *http.Request
+			w := serve(APIKeys(fakeVerifier{})(captured(&got)), r)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status != http.StatusNoContent {
+				return
+			}
+			p := auth.FromContext(got.Context())
+			if tt.key == "" {
+				if p != nil {
+					t.Fatalf("expected no principal, got %+v", p)
+				}
+				return
+			}
+			if p == nil || p.APIKeyID != tt.key || audit.Actor(got.Context()) != p.UserID {
+				t.Fatalf("expected key %q as principal and its user as actor, got %+v", tt.key, p)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	readKey := &auth.Principal{UserID: "u1", APIKeyID: "k1", Scopes: []string{auth.ScopeTasksRead}}
+	writeKey := &auth.Principal{UserID: "u1", APIKeyID: "k2", Scopes: []string{auth.ScopeTasksWrite}}
+	session := &auth.Principal{UserID: "u1", SessionID: "s1"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		method    string
+		status    int
+	}{
+		{name: "anonymous", method: http.MethodPost, status: http.StatusNoContent},
+		{name: "session", principal: session, method: http.MethodDelete, status: http.StatusNoContent},
+		{name: "read key reads", principal: readKey, method: http.MethodGet, status: http.StatusNoContent},
+		{name: "read key writes", principal: readKey, method: http.MethodPost, status: http.StatusForbidden},
+		{name: "write key reads", principal: writeKey, method: http.MethodHead, status: http.StatusNoContent},
+		{name: "write key writes", principal: writeKey, method: http.MethodPatch, status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/tasks", nil)
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+			var got *http.Request
+			w := serve(RequireScope(auth.ScopeTasksRead, auth.ScopeTasksWrite)(captured(&got)), r)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusForbidden && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("expected an insufficient_scope challenge")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/service"
+)
+
+// TokenVerifier resolves a bearer token to the principal it was issued to.
+type TokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// Authenticate reads a bearer access token from the Authorization header
+// and puts its principal in the request context, also as the audit actor.
+// Requests without the header, or already authenticated by an API key, go
+// through as they are; a token that does not verify is rejected with 401
+// rather than ignored.
+func Authenticate(v TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" || auth.FromContext(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request"`)
+				writeError(w, http.StatusUnauthorized, "Authorization header must be \"Bearer <token>\"")
+				return
+			}
+
+			p, err := v.VerifyAccessToken(r.Context(), strings.TrimSpace(token))
+			if errors.Is(err, service.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err != nil {
+				log.Printf("auth: verify token: %v", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), p)
+			ctx = audit.WithActor(ctx, p.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/service"
+)
+
+// fakeVerifier accepts "good" as a session token and "tapi_good" as an API
+// key; "boom" fails the way a broken database would.
+type fakeVerifier struct{}
+
+func (fakeVerifier) VerifyAccessToken(_ context.Context, token string) (*auth.Principal, error) {
+	switch token {
+	case "good":
+		return &auth.Principal{UserID: "u1", SessionID: "s1"}, nil
+	case "boom":
+		return nil, errors.New("db down")
+	}
+	return nil, service.ErrInvalidToken
+}
+
+func (fakeVerifier) VerifyAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	switch key {
+	case "tapi_good":
+		return &auth.Principal{UserID: "u2", APIKeyID: "k1", Scopes: []string{auth.ScopeTasksRead}, WorkspaceID: "w1"}, nil
+	case "tapi_boom":
+		return nil, errors.New("db down")
+	}
+	return nil, service.ErrInvalidAPIKey
+}
+
+// captured records the request that reached the end of the chain.
+func captured(got **http.Request) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got = r
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func withAuthorization(value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	if value != "" {
+		r.Header.Set("Authorization", value)
+	}
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		status    int
+		challenge string
+		user      string
+	}{
+		{name: "anonymous", status: http.StatusNoContent},
+		{name: "valid token", header: "Bearer good", status: http.StatusNoContent, user: "u1"},
+		{name: "scheme is case-insensitive", header: "bearer good", status: http.StatusNoContent, user: "u1"},
+		{name: "wrong scheme", header: "Basic dTpw", status: http.StatusUnauthorized, challenge: `Bearer error="invalid_request"`},
+		{name: "missing token", header: "Bearer", status: http.StatusUnauthorized, challenge: `Bearer error="invalid_request"`},
+		{name: "invalid token", header: "Bearer nope", status: http.StatusUnauthorized, challenge: `Bearer error="invalid_token"`},
+		{name: "verifier failure", header: "Bearer boom", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			w := serve(Authenticate(fakeVerifier{})(captured(&got)), withAuthorization(tt.header))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if c := w.Header().Get("WWW-Authenticate"); c != tt.challenge {
+				t.Fatalf("expected challenge %q, got %q", tt.challenge, c)
+			}
+			if tt.status != http.StatusNoContent {
+				return
+			}
+			p := auth.FromContext(got.Context())
+			if tt.user == "" {
+				if p != nil {
+					t.Fatalf("expected no principal, got %+v", p)
+				}
+				return
+			}
+			if p == nil || p.UserID != tt.user || audit.Actor(got.Context()) != tt.user {
+				t.Fatalf("expected %q as principal and actor, got %+v and %q", tt.user, p, audit.Actor(got.Context()))
+			}
+		})
+	}
+}
+
+func TestAuthenticate_LeavesAPIKeyPrincipalsAlone(t *testing.T) {
+	var got *http.Request
+	h := APIKeys(fakeVerifier{})(Authenticate(fakeVerifier{})(captured(&got)))
+
+	w := serve(h, withAuthorization("Bearer tapi_good"))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected the API key to be accepted, got %d", w.Code)
+	}
+	if p := auth.FromContext(got.Context()); p == nil || p.APIKeyID != "k1" {
+		t.Fatalf("expected the API key's principal, got %+v", p)
+	}
+}
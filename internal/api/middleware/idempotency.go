@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long a recorded response is replayed
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// DefaultIdempotencyMaxBody caps the bodies of requests with a key
+	DefaultIdempotencyMaxBody = 1_000_000
+
+	maxIdempotencyKey = 255
+	// bodies up to this size are held in memory, larger ones in a temp file
+	maxIdempotencyMemory = 1_000_000
+)
+
+// errSpool marks failures to set up the temp file for a large body
+var errSpool = errors.New("spool request body")
+
+// replayedHeaders are the response headers stored alongside the body
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Link"}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests carrying an
+// Idempotency-Key header safe to retry: the first response is recorded and
+// replayed for later requests with the same key and the same payload.
+// Reusing a key for a different payload is rejected with 422, and a retry
+// that races the original gets 409. Server errors are not recorded, so the
+// client may try again. Keys are scoped to the caller and workspace, so
+// nobody can replay someone else's response by guessing their key; it must
+// therefore run after authentication and workspace resolution. Bodies over
+// maxBody are rejected with 413.
+func Idempotency(store repository.IdempotencyRepository, ttl time.Duration, maxBody int64) func(http.Handler) http.Handler {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	if maxBody <= 0 {
+		maxBody = DefaultIdempotencyMaxBody
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || !mutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			requestHash, cleanup, err := bufferBody(w, r, maxBody)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				switch {
+				case errors.As(err, &tooLarge):
+					writeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+				case errors.Is(err, errSpool):
+					log.Printf("idempotency: %v", err)
+					writeError(w, http.StatusInternalServerError, "internal error")
+				default:
+					writeError(w, http.StatusBadRequest, "could not read request body")
+				}
+				return
+			}
+			defer cleanup()
+
+			key = tenant.WorkspaceID(r.Context()) + "/" + audit.Actor(r.Context()) + "/" + key
+			rec := &repository.IdempotencyRecord{
+				Key:         key,
+				RequestHash: requestHash,
+				ExpiresAt:   time.Now().Add(ttl),
+			}
+			existing, err := store.Reserve(r.Context(), rec)
+			if err != nil {
+				log.Printf("idempotency: reserve %q: %v", key, err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			if existing != nil {
+				switch {
+				case existing.RequestHash != rec.RequestHash:
+					writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+				case existing.StatusCode == 0:
+					writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				default:
+					replay(w, existing)
+				}
+				return
+			}
+
+			// Finish bookkeeping even if the client has gone away
+			ctx := context.WithoutCancel(r.Context())
+			rw := &recorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Also runs while a handler panic unwinds to Recoverer
+				if !completed {
+					if err := store.Release(ctx, key); err != nil {
+						log.Printf("idempotency: release %q: %v", key, err)
+					}
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			if rw.status >= http.StatusInternalServerError {
+				return
+			}
+			rec.StatusCode = rw.status
+			rec.Body = rw.body.Bytes()
+			rec.Header = http.Header{}
+			for _, h := range replayedHeaders {
+				if v := rw.Header().Values(h); len(v) > 0 {
+					rec.Header[h] = v
+				}
+			}
+			if err := store.Complete(ctx, rec); err != nil {
+				log.Printf("idempotency: complete %q: %v", key, err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHasher starts the fingerprint of what the key was used for, so the
+// same key cannot be replayed against another endpoint or payload.
+func requestHasher(r *http.Request) hash.Hash {
+	h := sha256.New()
+	io.WriteString(h, r.Method+"\n"+r.URL.RequestURI()+"\n"+r.Header.Get("Content-Type")+"\n")
+	return h
+}
+
+// bufferBody reads the request body up to limit, hashing it on the way,
+// and swaps in a copy the handler can read. Bodies that outgrow
+// maxIdempotencyMemory, such as uploads, spill into a temp file that
+// cleanup removes.
+func bufferBody(w http.ResponseWriter, r *http.Request, limit int64) (string, func(), error) {
+	h := requestHasher(r)
+	src := io.TeeReader(http.MaxBytesReader(w, r.Body, limit), h)
+
+	var mem bytes.Buffer
+	if _, err := io.CopyN(&mem, src, maxIdempotencyMemory+1); err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	if mem.Len() <= maxIdempotencyMemory {
+		r.Body = io.NopCloser(&mem)
+		return hex.EncodeToString(h.Sum(nil)), func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", errSpool, err)
+	}
+	cleanup := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if _, err := io.Copy(f, io.MultiReader(&mem, src)); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %v", errSpool, err)
+	}
+	r.Body = io.NopCloser(f)
+	return hex.EncodeToString(h.Sum(nil)), cleanup, nil
+}
+
+func replay(w http.ResponseWriter, rec *repository.IdempotencyRecord) {
+	for k, v := range rec.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.Header().Set("Content-Length", strconv.Itoa(len(rec.Body)))
+	w.WriteHeader(rec.StatusCode)
+	_, _ = w.Write(rec.Body)
+}
+
+// recorder tees the response so it can be stored after the handler returns.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recorder) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recorder) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// writeError mirrors the API's JSON envelope; this package cannot import it.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": nil, "error": msg})
+}
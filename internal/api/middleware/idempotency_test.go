@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]repository.IdempotencyRecord
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: map[string]repository.IdempotencyRecord{}}
+}
+
+func (f *fakeIdempotencyStore) Reserve(_ context.Context, rec *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[rec.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, nil
+	}
+	f.records[rec.Key] = *rec
+	return nil, nil
+}
+
+func (f *fakeIdempotencyStore) Complete(_ context.Context, rec *repository.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[rec.Key] = *rec
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, key)
+	return nil
+}
+
+func (f *fakeIdempotencyStore) DeleteExpired(context.Context) (int64, error) {
+	return 0, nil
+}
+
+// countingHandler answers 201 with a body numbering the calls it got.
+func countingHandler(calls *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Location", "/tasks/1")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"call":`+strconv.Itoa(*calls)+`}`)
+	})
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Idempotency-Key", key)
+	return r
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	var calls int
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(countingHandler(&calls))
+
+	first := serve(h, idempotentRequest("k1", `{"title":"a"}`))
+	second := serve(h, idempotentRequest("k1", `{"title":"a"}`))
+
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected a replay of %d %q, got %d %q", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" || second.Header().Get("Location") != "/tasks/1" {
+		t.Fatalf("unexpected replay headers %v", second.Header())
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first response marked as replayed")
+	}
+}
+
+func TestIdempotency_RejectsKeyReuseForAnotherPayload(t *testing.T) {
+	var calls int
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(countingHandler(&calls))
+
+	serve(h, idempotentRequest("k1", `{"title":"a"}`))
+	w := serve(h, idempotentRequest("k1", `{"title":"b"}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_ConflictWhileInFlight(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve(h, idempotentRequest("k1", `{}`)) }()
+	<-entered
+
+	if w := serve(h, idempotentRequest("k1", `{}`)); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while the original runs, got %d", w.Code)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("expected the original to finish with 201, got %d", w.Code)
+	}
+}
+
+func TestIdempotency_DoesNotRecordServerErrors(t *testing.T) {
+	var calls int
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	if w := serve(h, idempotentRequest("k1", `{}`)); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if w := serve(h, idempotentRequest("k1", `{}`)); w.Code != http.StatusCreated {
+		t.Fatalf("expected the retry to run, got %d", w.Code)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the handler to run twice, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_KeysAreScopedToTheCaller(t *testing.T) {
+	var calls int
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(countingHandler(&calls))
+
+	for _, actor := range []string{"alice", "bob"} {
+		r := idempotentRequest("k1", `{}`)
+		serve(h, r.WithContext(audit.WithActor(r.Context(), actor)))
+	}
+	if calls != 2 {
+		t.Fatalf("expected each caller's request to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_LargeBodies(t *testing.T) {
+	var got []byte
+	h := Idempotency(newFakeIdempotencyStore(), 0, 3*maxIdempotencyMemory)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	body := strings.Repeat("x", 2*maxIdempotencyMemory)
+	if w := serve(h, idempotentRequest("k1", body)); w.Code != http.StatusCreated {
+		t.Fatalf("expected a body over the memory limit to go through, got %d", w.Code)
+	}
+	if !bytes.Equal(got, []byte(body)) {
+		t.Fatalf("handler saw %d bytes, want %d", len(got), len(body))
+	}
+
+	tooLarge := strings.Repeat("x", 3*maxIdempotencyMemory+1)
+	if w := serve(h, idempotentRequest("k2", tooLarge)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestIdempotency_IgnoresSafeMethodsAndRequestsWithoutKey(t *testing.T) {
+	var calls int
+	h := Idempotency(newFakeIdempotencyStore(), 0, 0)(countingHandler(&calls))
+
+	serve(h, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`)))
+	serve(h, httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{}`)))
+	get := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	get.Header.Set("Idempotency-Key", "k1")
+	serve(h, get)
+	serve(h, get)
+
+	if calls != 4 {
+		t.Fatalf("expected every request to run, ran %d times", calls)
+	}
+}
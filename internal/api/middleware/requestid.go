@@ -1,16 +1,12 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
 
+	"github.com/Luc1808/TaskAPI/internal/audit"
 	"github.com/google/uuid"
 )
 
-type ctxKey string
-
-const requestIDKey ctxKey = "request_id"
-
 func RequestID() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,7 +15,7 @@ func RequestID() func(http.Handler) http.Handler {
 				reqID = uuid.New().String()
 			}
 			w.Header().Set("X-Request-ID", reqID)
-			ctx := context.WithValue(r.Context(), requestIDKey, reqID)
+			ctx := audit.WithRequestID(r.Context(), reqID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+)
+
+// WorkspaceResolver maps the slug a request asked for, possibly empty, to
+// the id of the workspace it works in.
+type WorkspaceResolver interface {
+	ResolveWorkspace(ctx context.Context, slug string) (string, error)
+}
+
+// Workspaces puts the request's workspace in its context. The slug comes
+// from the X-Workspace header or, when domain is set, from the subdomain
+// of Host (acme.tasks.example.com for domain tasks.example.com). It must
+// come after APIKeys and Authenticate.
+func Workspaces(res WorkspaceResolver, domain string) func(http.Handler) http.Handler {
+	domain = strings.ToLower(strings.Trim(domain, "."))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slug := strings.ToLower(strings.TrimSpace(r.Header.Get("X-Workspace")))
+			if slug == "" && domain != "" {
+				slug = subdomain(r.Host, domain)
+			}
+
+			id, err := res.ResolveWorkspace(r.Context(), slug)
+			switch {
+			case errors.Is(err, service.ErrWorkspaceNotFound):
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			case errors.Is(err, service.ErrWrongWorkspace):
+				writeError(w, http.StatusForbidden, err.Error())
+				return
+			case err != nil:
+				log.Printf("workspace: resolve %q: %v", slug, err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+
+			if id != "" {
+				r = r.WithContext(tenant.WithWorkspace(r.Context(), id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireWorkspace guards routes over workspace data. Anonymous requests
+// get 401, and signed-in users who belong to no workspace get 400.
+func RequireWorkspace() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tenant.WorkspaceID(r.Context()) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if auth.FromContext(r.Context()) == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, service.ErrUnauthenticated.Error())
+				return
+			}
+			writeError(w, http.StatusBadRequest, service.ErrNoWorkspace.Error())
+		})
+	}
+}
+
+// subdomain returns the label host has in front of domain, or "" when host
+// is domain itself or lies outside it.
+func subdomain(host, domain string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	label, ok := strings.CutSuffix(host, "."+domain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+)
+
+// fakeResolver knows the workspaces "acme" (w1) and "other" (w2), and
+// falls back to w1 when no slug was sent.
+type fakeResolver struct {
+	slugs []string
+}
+
+func (f *fakeResolver) ResolveWorkspace(_ context.Context, slug string) (string, error) {
+	f.slugs = append(f.slugs, slug)
+	switch slug {
+	case "", "acme":
+		return "w1", nil
+	case "other":
+		return "", service.ErrWrongWorkspace
+	case "boom":
+		return "", errors.New("db down")
+	}
+	return "", service.ErrWorkspaceNotFound
+}
+
+func TestSubdomain(t *testing.T) {
+	tests := []struct {
+		host, want string
+	}{
+		{"acme.tasks.example.com", "acme"},
+		{"ACME.tasks.example.com:8080", "acme"},
+		{"acme.tasks.example.com.", "acme"},
+		{"tasks.example.com", ""},
+		{"a.b.tasks.example.com", ""},
+		{"acme.example.com", ""},
+		{"acmetasks.example.com", ""},
+		{"localhost:8080", ""},
+	}
+	for _, tt := range tests {
+		if got := subdomain(tt.host, "tasks.example.com"); got != tt.want {
+			t.Errorf("subdomain(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		host      string
+		status    int
+		workspace string
+		slug      string
+	}{
+		{name: "no slug", status: http.StatusNoContent, workspace: "w1"},
+		{name: "header", header: "Acme", status: http.StatusNoContent, workspace: "w1", slug: "acme"},
+		{name: "subdomain", host: "acme.tasks.example.com", status: http.StatusNoContent, workspace: "w1", slug: "acme"},
+		{name: "header wins over subdomain", header: "missing", host: "acme.tasks.example.com", status: http.StatusNotFound, slug: "missing"},
+		{name: "unknown workspace", header: "missing", status: http.StatusNotFound, slug: "missing"},
+		{name: "api key of another workspace", header: "other", status: http.StatusForbidden, slug: "other"},
+		{name: "resolver failure", header: "boom", status: http.StatusInternalServerError, slug: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Workspace", tt.header)
+			}
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			res := &fakeResolver{}
+			var got *http.Request
+			w := serve(Workspaces(res, "tasks.example.com")(captured(&got)), r)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if len(res.slugs) != 1 || res.slugs[0] != tt.slug {
+				t.Fatalf("expected the resolver to get %q, got %q", tt.slug, res.slugs)
+			}
+			if tt.status == http.StatusNoContent && tenant.WorkspaceID(got.Context()) != tt.workspace {
+				t.Fatalf("expected workspace %q, got %q", tt.workspace, tenant.WorkspaceID(got.Context()))
+			}
+		})
+	}
+}
+
+func TestWorkspaces_IgnoresHostWithoutDomain(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	r.Host = "missing.tasks.example.com"
+	res := &fakeResolver{}
+	var got *http.Request
+
+	if w := serve(Workspaces(res, "")(captured(&got)), r); w.Code != http.StatusNoContent {
+		t.Fatalf("expected the host to be ignored, got %d", w.Code)
+	}
+	if res.slugs[0] != "" {
+		t.Fatalf("expected no slug, got %q", res.slugs[0])
+	}
+}
+
+func TestRequireWorkspace(t *testing.T) {
+	signedIn := func(r *http.Request) *http.Request {
+		return r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{UserID: "u1", SessionID: "s1"}))
+	}
+	inWorkspace := func(r *http.Request) *http.Request {
+		return r.WithContext(tenant.WithWorkspace(r.Context(), "w1"))
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(*http.Request) *http.Request
+		status int
+	}{
+		{name: "anonymous", setup: func(r *http.Request) *http.Request { return r }, status: http.StatusUnauthorized},
+		{name: "no workspace", setup: signedIn, status: http.StatusBadRequest},
+		{name: "workspace", setup: func(r *http.Request) *http.Request { return inWorkspace(signedIn(r)) }, status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			w := serve(RequireWorkspace()(captured(&got)), tt.setup(httptest.NewRequest(http.MethodGet, "/tasks", nil)))
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("expected a challenge for anonymous requests")
+			}
+		})
+	}
+}
@@ -4,35 +4,165 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/Luc1808/TaskAPI/internal/api/middleware"
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/repository"
 	"github.com/Luc1808/TaskAPI/internal/service"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 )
 
-func NewRouter(taskSvc *service.TaskService) http.Handler {
+// Services bundles everything the HTTP layer talks to.
+type Services struct {
+	Tasks        *service.TaskService
+	Tags         *service.TagService
+	Dependencies *service.DependencyService
+	Statuses     *service.StatusService
+	History      *service.HistoryService
+	Comments     *service.CommentService
+	Attachments  *service.AttachmentService
+	Users        *service.UserService
+	Tokens       *service.TokenService
+	APIKeys      *service.APIKeyService
+	Workspaces   *service.WorkspaceService
+
+	// WorkspaceDomain, when set, lets the subdomain of Host pick the
+	// workspace, as an alternative to the X-Workspace header
+	WorkspaceDomain string
+
+	// Idempotency enables Idempotency-Key handling when set
+	Idempotency    repository.IdempotencyRepository
+	IdempotencyTTL time.Duration
+	// IdempotencyMaxBody caps request bodies sent with an Idempotency-Key
+	IdempotencyMaxBody int64
+}
+
+func NewRouter(svc Services) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(chimw.Logger)
 	r.Use(chimw.Recoverer)
 	r.Use(middleware.RequestID())
+	r.Use(middleware.APIKeys(svc.APIKeys))
+	r.Use(middleware.Authenticate(svc.Tokens))
+	r.Use(middleware.Workspaces(svc.Workspaces, svc.WorkspaceDomain))
+
+	h := NewTaskHandler(svc.Tasks)
+	th := NewTagHandler(svc.Tags)
+	dh := NewDependencyHandler(svc.Dependencies)
+	sh := NewStatusHandler(svc.Statuses)
+	hh := NewHistoryHandler(svc.History)
+	ch := NewCommentHandler(svc.Comments)
+	ah := NewAttachmentHandler(svc.Attachments)
+	uh := NewAuthHandler(svc.Users, svc.Tokens)
+	kh := NewAPIKeyHandler(svc.APIKeys)
+	mh := NewMemberHandler(svc.Tasks)
+	wh := NewWorkspaceHandler(svc.Workspaces)
 
-	h := NewTaskHandler(taskSvc)
+	// API keys only reach the task API, as far as their scopes allow
+	scoped := middleware.RequireScope(auth.ScopeTasksRead, auth.ScopeTasksWrite)
+	// tasks, tags and statuses live in a workspace
+	inWorkspace := middleware.RequireWorkspace()
+	// retries of task writes may carry an Idempotency-Key. Only task routes
+	// honour it, so tokens and API keys never end up in the stored responses.
+	var idempotent chi.Middlewares
+	if svc.Idempotency != nil {
+		idempotent = append(idempotent, middleware.Idempotency(svc.Idempotency, svc.IdempotencyTTL, svc.IdempotencyMaxBody))
+	}
 
 	r.Get("/healthz", h.HealthHandler)
+	r.Get("/.well-known/jwks.json", uh.JWKS)
+
+	r.Route("/auth", func(ar chi.Router) {
+		ar.Post("/register", uh.Register)
+		ar.Post("/login", uh.Login)
+		ar.Post("/refresh", uh.Refresh)
+		ar.Post("/logout", uh.Logout)
+		ar.Post("/logout-all", uh.LogoutAll)
+		ar.Get("/me", uh.Me)
+		ar.Post("/password", uh.ChangePassword)
+		ar.Post("/password-reset", uh.RequestPasswordReset)
+		ar.Post("/password-reset/confirm", uh.ResetPassword)
+	})
+
+	r.Route("/api-keys", func(kr chi.Router) {
+		kr.Get("/", kh.ListAPIKeys)
+		kr.Post("/", kh.CreateAPIKey)
+		kr.Delete("/{id}", kh.RevokeAPIKey)
+	})
+
+	r.Route("/workspaces", func(wr chi.Router) {
+		wr.Get("/", wh.ListWorkspaces)
+		wr.Post("/", wh.CreateWorkspace)
+		wr.Get("/{slug}/members", wh.ListMembers)
+		wr.Put("/{slug}/members", wh.SetMember)
+		wr.Delete("/{slug}/members/{userID}", wh.RemoveMember)
+	})
 
+	r.With(scoped, inWorkspace).With(idempotent...).Post("/tasks:batch", h.BatchTasks)
 	r.Route("/tasks", func(tr chi.Router) {
+		tr.Use(scoped, inWorkspace)
+		tr.Use(idempotent...)
 		tr.Get("/", h.ListTasks)
 		tr.Post("/", h.CreateTask)
 
+		tr.Get("/trash", h.ListTrash)
+		tr.Delete("/trash", h.EmptyTrash)
+		tr.Delete("/trash/{id}", h.PurgeTask)
+
 		tr.Route("/{id}", func(ir chi.Router) {
 			ir.Get("/", h.GetTask)
 			ir.Put("/", h.UpdateTask)
+			ir.Patch("/", h.PatchTask)
 			ir.Delete("/", h.DeleteTask)
+			ir.Get("/children", h.ListChildren)
+			ir.Get("/subtree", h.GetSubtree)
+			ir.Post("/restore", h.RestoreTask)
+			ir.Post("/archive", h.ArchiveTask)
+			ir.Post("/unarchive", h.UnarchiveTask)
+			ir.Get("/history", hh.GetHistory)
+
+			ir.Get("/members", mh.ListMembers)
+			ir.Put("/members", mh.SetMember)
+			ir.Delete("/members/{userID}", mh.RemoveMember)
+
+			ir.Get("/comments", ch.ListComments)
+			ir.Post("/comments", ch.CreateComment)
+			ir.Put("/comments/{commentID}", ch.UpdateComment)
+			ir.Delete("/comments/{commentID}", ch.DeleteComment)
+
+			ir.Get("/attachments", ah.ListAttachments)
+			ir.Post("/attachments", ah.UploadAttachment)
+			ir.Get("/attachments/{attachmentID}", ah.DownloadAttachment)
+			ir.Delete("/attachments/{attachmentID}", ah.DeleteAttachment)
+
+			ir.Put("/tags/{tagID}", th.AttachTag)
+			ir.Delete("/tags/{tagID}", th.DetachTag)
+
+			ir.Get("/blockers", dh.ListBlockers)
+			ir.Put("/blockers/{blockerID}", dh.AddBlocker)
+			ir.Delete("/blockers/{blockerID}", dh.RemoveBlocker)
 		})
 	})
 
+	r.Route("/tags", func(tr chi.Router) {
+		tr.Use(scoped, inWorkspace)
+		tr.Get("/", th.ListTags)
+		tr.Post("/", th.CreateTag)
+		tr.Put("/{id}", th.RenameTag)
+		tr.Delete("/{id}", th.DeleteTag)
+	})
+
+	r.Route("/statuses", func(sr chi.Router) {
+		sr.Use(scoped, inWorkspace)
+		sr.Get("/", sh.ListStatuses)
+		sr.Post("/", sh.CreateStatus)
+		sr.Put("/{name}", sh.UpdateStatus)
+		sr.Delete("/{name}", sh.DeleteStatus)
+	})
+
 	return r
 }
 
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type StatusHandler struct {
+	svc *service.StatusService
+}
+
+func NewStatusHandler(svc *service.StatusService) *StatusHandler {
+	return &StatusHandler{svc: svc}
+}
+
+func (h *StatusHandler) ListStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.svc.ListStatuses(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (h *StatusHandler) CreateStatus(w http.ResponseWriter, r *http.Request) {
+	var req service.StatusInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status, err := h.svc.CreateStatus(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, status)
+}
+
+func (h *StatusHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	var req service.StatusInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status, err := h.svc.UpdateStatus(r.Context(), name, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *StatusHandler) DeleteStatus(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	if err := h.svc.DeleteStatus(r.Context(), name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
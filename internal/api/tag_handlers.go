@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type TagHandler struct {
+	svc *service.TagService
+}
+
+func NewTagHandler(svc *service.TagService) *TagHandler {
+	return &TagHandler{svc: svc}
+}
+
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.svc.ListTags(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req service.TagInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tag, err := h.svc.CreateTag(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+func (h *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req service.TagInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	tag, err := h.svc.RenameTag(r.Context(), id, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tag)
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.svc.DeleteTag(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) AttachTag(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+	tagID := chi.URLParam(r, "tagID")
+
+	task, err := h.svc.AttachTag(r.Context(), taskID, tagID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (h *TagHandler) DetachTag(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+	tagID := chi.URLParam(r, "tagID")
+
+	task, err := h.svc.DetachTag(r.Context(), taskID, tagID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, task)
+}
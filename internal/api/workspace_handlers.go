@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Luc1808/TaskAPI/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type WorkspaceHandler struct {
+	svc *service.WorkspaceService
+}
+
+func NewWorkspaceHandler(svc *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{svc: svc}
+}
+
+func (h *WorkspaceHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := h.svc.ListWorkspaces(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, workspaces)
+}
+
+func (h *WorkspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req service.WorkspaceInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	workspace, err := h.svc.CreateWorkspace(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, workspace)
+}
+
+func (h *WorkspaceHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := h.svc.ListMembers(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, members)
+}
+
+func (h *WorkspaceHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	var req service.WorkspaceMemberInput
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	member, err := h.svc.SetMember(r.Context(), chi.URLParam(r, "slug"), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, member)
+}
+
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.RemoveMember(r.Context(), chi.URLParam(r, "slug"), chi.URLParam(r, "userID")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,32 @@
+// Package audit carries who is making a request, and which request it is,
+// from the HTTP layer down to the repositories that record task events.
+package audit
+
+import "context"
+
+type ctxKey int
+
+const (
+	actorKey ctxKey = iota
+	requestIDKey
+)
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor is the id of the authenticated user, or empty for anonymous
+// requests.
+func Actor(ctx context.Context) string {
+	v, _ := ctx.Value(actorKey).(string)
+	return v
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	v, _ := ctx.Value(requestIDKey).(string)
+	return v
+}
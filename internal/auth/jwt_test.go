@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJWKS(t *testing.T) []byte {
+	t.Helper()
+	b64 := base64.RawURLEncoding
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jwks{Keys: []jwk{
+		{Kty: "oct", Kid: "hs", K: b64.EncodeToString([]byte(strings.Repeat("s", 32)))},
+		{
+			Kty: "RSA", Kid: "rs",
+			N: b64.EncodeToString(rsaKey.N.Bytes()),
+			E: "AQAB",
+			D: b64.EncodeToString(rsaKey.D.Bytes()),
+			P: b64.EncodeToString(rsaKey.Primes[0].Bytes()),
+			Q: b64.EncodeToString(rsaKey.Primes[1].Bytes()),
+		},
+		{
+			Kty: "OKP", Kid: "ed", Crv: "Ed25519",
+			X: b64.EncodeToString(edKey.Public().(ed25519.PublicKey)),
+			D: b64.EncodeToString(edKey.Seed()),
+		},
+	}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSignVerifyEachAlg(t *testing.T) {
+	keys, err := ParseJWKS(testJWKS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	for _, kid := range []string{"hs", "rs", "ed"} {
+		ks, err := NewKeySet(kid, keys...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := ks.Sign(Claims{Subject: "u1", SessionID: "s1", ExpiresAt: now.Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", kid, err)
+		}
+		c, err := ks.Verify(token, now)
+		if err != nil {
+			t.Fatalf("%s: verify: %v", kid, err)
+		}
+		if c.Subject != "u1" || c.SessionID != "s1" {
+			t.Fatalf("%s: unexpected claims %+v", kid, c)
+		}
+
+		// flip a byte of the payload
+		parts := strings.Split(token, ".")
+		parts[1] = parts[1][:len(parts[1])-2] + "AA"
+		if _, err := ks.Verify(strings.Join(parts, "."), now); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("%s: tampered token accepted: %v", kid, err)
+		}
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	key, _ := GenerateHMACKey("k1")
+	ks, _ := NewKeySet("", key)
+	now := time.Now()
+
+	token, _ := ks.Sign(Claims{Subject: "u1", ExpiresAt: now.Unix()})
+	if _, err := ks.Verify(token, now); err != nil {
+		t.Fatalf("token within leeway rejected: %v", err)
+	}
+	if _, err := ks.Verify(token, now.Add(time.Minute)); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, _ := GenerateHMACKey("old")
+	newKey, _ := GenerateHMACKey("new")
+	exp := time.Now().Add(time.Minute).Unix()
+
+	before, _ := NewKeySet("old", oldKey)
+	oldToken, _ := before.Sign(Claims{Subject: "u1", ExpiresAt: exp})
+
+	during, err := NewKeySet("new", oldKey, newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := during.Verify(oldToken, time.Now()); err != nil {
+		t.Fatalf("token from the previous key rejected: %v", err)
+	}
+	newToken, _ := during.Sign(Claims{Subject: "u1", ExpiresAt: exp})
+	if !strings.Contains(decodeHeader(t, newToken), `"kid":"new"`) {
+		t.Fatalf("new tokens not signed with the new key")
+	}
+
+	after, _ := NewKeySet("", newKey)
+	if _, err := after.Verify(oldToken, time.Now()); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token from a retired key accepted: %v", err)
+	}
+}
+
+func TestVerifyRejectsAlgConfusion(t *testing.T) {
+	keys, err := ParseJWKS(testJWKS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, _ := NewKeySet("hs", keys...)
+	token, _ := ks.Sign(Claims{Subject: "u1", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	b64 := base64.RawURLEncoding
+	parts := strings.Split(token, ".")
+	for _, h := range []string{`{"alg":"HS256","kid":"rs"}`, `{"alg":"none","kid":"hs"}`} {
+		forged := b64.EncodeToString([]byte(h)) + "." + parts[1] + "." + parts[2]
+		if _, err := ks.Verify(forged, time.Now()); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("%s accepted: %v", h, err)
+		}
+	}
+}
+
+func TestPublicJWKSOmitsSecrets(t *testing.T) {
+	keys, err := ParseJWKS(testJWKS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, _ := NewKeySet("", keys...)
+	out, err := ks.PublicJWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var set jwks
+	if err := json.Unmarshal(out, &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected the RSA and Ed25519 keys only, got %s", out)
+	}
+	for _, k := range set.Keys {
+		if k.D != "" || k.K != "" {
+			t.Fatalf("private material published: %s", out)
+		}
+	}
+
+	// the published set verifies tokens on its own
+	pub, err := ParseJWKS(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, _ := NewKeySet("ed", keys...)
+	token, _ := signer.Sign(Claims{Subject: "u1", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	verifier := &KeySet{keys: pub}
+	if _, err := verifier.Verify(token, time.Now()); err != nil {
+		t.Fatalf("verify with published keys: %v", err)
+	}
+}
+
+func decodeHeader(t *testing.T, token string) string {
+	t.Helper()
+	raw, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
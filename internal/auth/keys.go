@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	minHMACKeyLen = 32
+	minRSAKeyBits = 2048
+)
+
+// Key is one signing or verification key. Keys loaded without private
+// material can only verify.
+type Key struct {
+	ID  string
+	Alg string
+
+	secret  []byte
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// NewHMACKey returns an HS256 key; the secret must be at least 32 bytes.
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < minHMACKeyLen {
+		return nil, fmt.Errorf("key %q: HS256 secret must be at least %d bytes", id, minHMACKeyLen)
+	}
+	return &Key{ID: id, Alg: HS256, secret: secret}, nil
+}
+
+// GenerateHMACKey returns a random HS256 key.
+func GenerateHMACKey(id string) (*Key, error) {
+	secret := make([]byte, minHMACKeyLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return NewHMACKey(id, secret)
+}
+
+func (k *Key) canSign() bool {
+	return k.secret != nil || k.private != nil
+}
+
+func (k *Key) sign(data []byte) ([]byte, error) {
+	switch k.Alg {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case RS256:
+		digest := sha256.Sum256(data)
+		return k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case EdDSA:
+		return k.private.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	return nil, fmt.Errorf("unsupported alg %q", k.Alg)
+}
+
+func (k *Key) verify(data, sig []byte) bool {
+	switch k.Alg {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(data)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+	case EdDSA:
+		return ed25519.Verify(k.public.(ed25519.PublicKey), data, sig)
+	}
+	return false
+}
+
+// KeySet holds every key tokens may be verified with, and the one new
+// tokens are signed with. Rotating keys means adding the new key, making
+// it the signing key, and dropping the old one once the tokens it signed
+// have expired.
+type KeySet struct {
+	keys    []*Key
+	signing *Key
+}
+
+// NewKeySet signs with the key named signingID, or with the first key
+// that has private material when signingID is empty.
+func NewKeySet(signingID string, keys ...*Key) (*KeySet, error) {
+	ks := &KeySet{}
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if seen[k.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		seen[k.ID] = true
+		ks.keys = append(ks.keys, k)
+
+		if ks.signing == nil && k.canSign() && (signingID == "" || k.ID == signingID) {
+			ks.signing = k
+		}
+	}
+	if ks.signing == nil {
+		if signingID != "" {
+			return nil, fmt.Errorf("no private key with id %q", signingID)
+		}
+		return nil, errors.New("key set has no private key to sign with")
+	}
+	return ks, nil
+}
+
+func (ks *KeySet) lookup(id string) *Key {
+	if id == "" && len(ks.keys) == 1 {
+		return ks.keys[0]
+	}
+	for _, k := range ks.keys {
+		if k.ID == id {
+			return k
+		}
+	}
+	return nil
+}
+
+// jwk is a JSON Web Key (RFC 7517) with the fields the supported key
+// types use.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+
+	K string `json:"k,omitempty"`
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	X string `json:"x,omitempty"`
+	D string `json:"d,omitempty"`
+	P string `json:"p,omitempty"`
+	Q string `json:"q,omitempty"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// ParseJWKS reads keys from a JWK Set document. oct keys are used with
+// HS256, RSA keys with RS256 and Ed25519 OKP keys with EdDSA; keys that
+// include private parameters can sign.
+func ParseJWKS(data []byte) ([]*Key, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse key set: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(set.Keys))
+	for _, j := range set.Keys {
+		if j.Kid == "" {
+			return nil, errors.New("every key needs a kid")
+		}
+		k, err := parseJWK(j)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", j.Kid, err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func parseJWK(j jwk) (*Key, error) {
+	b64 := base64.RawURLEncoding
+	checkAlg := func(want string) error {
+		if j.Alg != "" && j.Alg != want {
+			return fmt.Errorf("alg %q is not supported for kty %q", j.Alg, j.Kty)
+		}
+		return nil
+	}
+
+	switch j.Kty {
+	case "oct":
+		if err := checkAlg(HS256); err != nil {
+			return nil, err
+		}
+		secret, err := b64.DecodeString(j.K)
+		if err != nil {
+			return nil, errors.New("invalid k")
+		}
+		return NewHMACKey(j.Kid, secret)
+
+	case "RSA":
+		if err := checkAlg(RS256); err != nil {
+			return nil, err
+		}
+		n, err1 := decodeBigInt(j.N)
+		e, err2 := decodeBigInt(j.E)
+		if err1 != nil || err2 != nil || !e.IsInt64() {
+			return nil, errors.New("invalid n or e")
+		}
+		pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		k := &Key{ID: j.Kid, Alg: RS256, public: pub}
+		if j.D == "" {
+			return k, nil
+		}
+		d, err1 := decodeBigInt(j.D)
+		p, err2 := decodeBigInt(j.P)
+		q, err3 := decodeBigInt(j.Q)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, errors.New("private RSA keys need d, p and q")
+		}
+		priv := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+		if err := priv.Validate(); err != nil {
+			return nil, err
+		}
+		priv.Precompute()
+		k.private = priv
+		return k, nil
+
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("curve %q is not supported", j.Crv)
+		}
+		if err := checkAlg(EdDSA); err != nil {
+			return nil, err
+		}
+		x, err := b64.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid x")
+		}
+		k := &Key{ID: j.Kid, Alg: EdDSA, public: ed25519.PublicKey(x)}
+		if j.D == "" {
+			return k, nil
+		}
+		seed, err := b64.DecodeString(j.D)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, errors.New("invalid d")
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		if !priv.Public().(ed25519.PublicKey).Equal(k.public) {
+			return nil, errors.New("d does not match x")
+		}
+		k.private = priv
+		return k, nil
+	}
+	return nil, fmt.Errorf("kty %q is not supported", j.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// PublicJWKS renders the asymmetric keys as a JWK Set that clients can
+// verify tokens with. HS256 secrets are never included.
+func (ks *KeySet) PublicJWKS() ([]byte, error) {
+	b64 := base64.RawURLEncoding
+	set := jwks{Keys: []jwk{}}
+	for _, k := range ks.keys {
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA", Kid: k.ID, Alg: k.Alg, Use: "sig",
+				N: b64.EncodeToString(pub.N.Bytes()),
+				E: b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kty: "OKP", Kid: k.ID, Alg: k.Alg, Use: "sig", Crv: "Ed25519",
+				X: b64.EncodeToString(pub),
+			})
+		}
+	}
+	return json.Marshal(set)
+}
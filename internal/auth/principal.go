@@ -0,0 +1,58 @@
+// Package auth signs and verifies the JWT access tokens the API hands out,
+// and carries the authenticated principal through request contexts.
+package auth
+
+import "context"
+
+// Scopes an API key can be limited to.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from JWTs and
+// makes leaked keys easy to search for.
+const APIKeyPrefix = "tapi_"
+
+// Scopes lists every scope a key can be granted.
+var Scopes = []string{ScopeTasksRead, ScopeTasksWrite}
+
+// Principal is whoever a request is authenticated as.
+type Principal struct {
+	UserID string
+	// SessionID is the login session the access token was issued for
+	SessionID string
+	// APIKeyID, Scopes and WorkspaceID are set when an API key was used
+	// instead; the key only works in that workspace
+	APIKeyID    string
+	Scopes      []string
+	WorkspaceID string
+}
+
+// Allows reports whether the principal may act within scope. Login
+// sessions may do anything, and tasks:write implies tasks:read.
+func (p *Principal) Allows(scope string) bool {
+	if p.APIKeyID == "" {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeTasksWrite && scope == ScopeTasksRead {
+			return true
+		}
+	}
+	return false
+}
+
+type ctxKey int
+
+const principalKey ctxKey = iota
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// FromContext returns nil for anonymous requests.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "tasks/t1/a1", strings.NewReader("log line"), 8, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := s.Get(ctx, "tasks/t1/a1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(body) != "log line" {
+		t.Fatalf("Get returned %q", body)
+	}
+
+	if err := s.Put(ctx, "tasks/t1/short", strings.NewReader("abc"), 8, ""); err == nil {
+		t.Fatal("expected a short body to fail")
+	}
+	if _, err := s.Get(ctx, "tasks/t1/short"); !errors.Is(err, models.ErrBlobNotFound) {
+		t.Fatalf("expected a failed Put to leave nothing behind, got %v", err)
+	}
+
+	if err := s.Delete(ctx, "tasks/t1/a1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "tasks/t1/a1"); err != nil {
+		t.Fatalf("expected deleting twice to succeed, got %v", err)
+	}
+	for _, key := range []string{"../x", "tasks/../../x", "/abs", "a//b"} {
+		if _, err := s.Get(ctx, key); err == nil || errors.Is(err, models.ErrBlobNotFound) {
+			t.Fatalf("expected key %q to be rejected, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Package jobs runs periodic background work inside the API process.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs fn once per interval until ctx is cancelled. Failures are
+// logged and retried on the next tick.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					log.Printf("job %s: %v", name, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// APIKeyRepository scopes keys by owner, so a key id paired with another
+// user is ErrAPIKeyNotFound.
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error)
+	// List is ordered newest first
+	List(ctx context.Context, userID string) ([]models.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Touch records that the key was just used. It writes at most once a
+	// minute per key, so busy scripts don't turn every request into a write.
+	Touch(ctx context.Context, id string) error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"io"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// BlobStore keeps the bytes of attachments. Keys are opaque paths made of
+// [a-zA-Z0-9/._-]. Get reports models.ErrBlobNotFound for unknown keys,
+// while deleting one is not an error.
+type BlobStore interface {
+	// Put stores exactly size bytes read from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentRepository stores attachment metadata. Deleting a row, directly
+// or through a purged task, queues its blob in orphaned_blobs until the
+// blob itself is removed and ForgetBlobs is called.
+type AttachmentRepository interface {
+	Create(ctx context.Context, a *models.Attachment) (*models.Attachment, error)
+	GetByID(ctx context.Context, taskID, id string) (*models.Attachment, error)
+	// List is ordered oldest first
+	List(ctx context.Context, taskID string) ([]models.Attachment, error)
+	Delete(ctx context.Context, taskID, id string) error
+
+	OrphanedBlobs(ctx context.Context, limit int) ([]string, error)
+	ForgetBlobs(ctx context.Context, keys []string) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// CommentRepository looks comments up under their task, so a comment id
+// paired with the wrong task is ErrCommentNotFound.
+type CommentRepository interface {
+	Create(ctx context.Context, c *models.Comment) (*models.Comment, error)
+	GetByID(ctx context.Context, taskID, id string) (*models.Comment, error)
+	// List is ordered oldest first
+	List(ctx context.Context, taskID string, p Pagination) ([]models.Comment, error)
+	Count(ctx context.Context, taskID string) (int, error)
+	// Update only changes the body
+	Update(ctx context.Context, c *models.Comment) (*models.Comment, error)
+	Delete(ctx context.Context, taskID, id string) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "context"
+
+// MaxDependencyVisits bounds how many tasks Add walks while looking for a
+// cycle.
+const MaxDependencyVisits = 10_000
+
+// DependencyRepository stores "task is blocked by blocker" edges.
+type DependencyRepository interface {
+	// Add is idempotent and reports models.ErrNotFound if either task is
+	// missing. It refuses an edge that would close a cycle with
+	// models.ErrDependencyCycle, or models.ErrDependencyGraphTooLarge when
+	// blockerID's blockers reach past MaxDependencyVisits tasks. Concurrent
+	// Adds in a workspace are serialized, so two of them can't close a
+	// cycle together.
+	Add(ctx context.Context, taskID, blockerID string) error
+	Remove(ctx context.Context, taskID, blockerID string) error
+}
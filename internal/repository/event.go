@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// TaskEventRepository reads the audit trail. Events are written by the
+// database itself whenever TaskRepository changes a task, so there is no
+// way to add or edit them here.
+type TaskEventRepository interface {
+	// List is ordered newest first
+	List(ctx context.Context, taskID string, p Pagination) ([]models.TaskEvent, error)
+	Count(ctx context.Context, taskID string) (int, error)
+}
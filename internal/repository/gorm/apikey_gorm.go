@@ -0,0 +1,114 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+type APIKeyRow struct {
+	ID          string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID      string     `gorm:"column:user_id;type:uuid;not null"`
+	WorkspaceID string     `gorm:"column:workspace_id;type:uuid;not null"`
+	Name        string     `gorm:"column:name;type:text;not null"`
+	Prefix      string     `gorm:"column:prefix;type:text;not null"`
+	KeyHash     string     `gorm:"column:key_hash;type:text;not null;unique"`
+	Scopes      string     `gorm:"column:scopes;type:text;not null"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (APIKeyRow) TableName() string { return "public.api_keys" }
+
+func apiKeyToDomain(r *APIKeyRow) *models.APIKey {
+	return &models.APIKey{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		WorkspaceID: r.WorkspaceID,
+		Name:        r.Name,
+		Prefix:      r.Prefix,
+		KeyHash:     r.KeyHash,
+		Scopes:      strings.Fields(r.Scopes),
+		ExpiresAt:   r.ExpiresAt,
+		LastUsedAt:  r.LastUsedAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	row := &APIKeyRow{
+		UserID:      k.UserID,
+		WorkspaceID: k.WorkspaceID,
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		KeyHash:     k.KeyHash,
+		Scopes:      strings.Join(k.Scopes, " "),
+		ExpiresAt:   k.ExpiresAt,
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return nil, err
+	}
+
+	return apiKeyToDomain(row), nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context, userID string) ([]models.APIKey, error) {
+	var rows []APIKeyRow
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.APIKey, 0, len(rows))
+	for i := range rows {
+		out = append(out, *apiKeyToDomain(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var row APIKeyRow
+	err := r.db.WithContext(ctx).First(&row, "key_hash = ?", keyHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeyToDomain(&row), nil
+}
+
+func (r *APIKeyRepo) Delete(ctx context.Context, userID, id string) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&APIKeyRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepo) Touch(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&APIKeyRow{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')", id).
+		Update("last_used_at", gorm.Expr("now()")).Error
+}
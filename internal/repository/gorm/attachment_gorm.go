@@ -0,0 +1,126 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepo(db *gorm.DB) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+type AttachmentRow struct {
+	ID          string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	TaskID      string    `gorm:"column:task_id;type:uuid;not null"`
+	Filename    string    `gorm:"column:filename;type:text;not null"`
+	ContentType string    `gorm:"column:content_type;type:text;not null"`
+	Size        int64     `gorm:"column:size_bytes;not null"`
+	SHA256      string    `gorm:"column:sha256;type:text;not null"`
+	StorageKey  string    `gorm:"column:storage_key;type:text;not null;unique"`
+	UploadedBy  string    `gorm:"column:uploaded_by;type:text;not null;default:''"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AttachmentRow) TableName() string { return "public.task_attachments" }
+
+type OrphanedBlobRow struct {
+	StorageKey string    `gorm:"column:storage_key;type:text;primaryKey"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (OrphanedBlobRow) TableName() string { return "public.orphaned_blobs" }
+
+func attachmentToDomain(r *AttachmentRow) *models.Attachment {
+	return &models.Attachment{
+		ID:          r.ID,
+		TaskID:      r.TaskID,
+		Filename:    r.Filename,
+		ContentType: r.ContentType,
+		Size:        r.Size,
+		SHA256:      r.SHA256,
+		StorageKey:  r.StorageKey,
+		UploadedBy:  r.UploadedBy,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func (r *AttachmentRepo) Create(ctx context.Context, a *models.Attachment) (*models.Attachment, error) {
+	row := &AttachmentRow{
+		TaskID:      a.TaskID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		SHA256:      a.SHA256,
+		StorageKey:  a.StorageKey,
+		UploadedBy:  a.UploadedBy,
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return attachmentToDomain(row), nil
+}
+
+func (r *AttachmentRepo) GetByID(ctx context.Context, taskID, id string) (*models.Attachment, error) {
+	var row AttachmentRow
+	err := r.db.WithContext(ctx).First(&row, "id = ? AND task_id = ?", id, taskID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentToDomain(&row), nil
+}
+
+func (r *AttachmentRepo) List(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	var rows []AttachmentRow
+	if err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Attachment, len(rows))
+	for i := range rows {
+		out[i] = *attachmentToDomain(&rows[i])
+	}
+	return out, nil
+}
+
+func (r *AttachmentRepo) Delete(ctx context.Context, taskID, id string) error {
+	tx := r.db.WithContext(ctx).Where("id = ? AND task_id = ?", id, taskID).Delete(&AttachmentRow{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *AttachmentRepo) OrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	out := []string{}
+	err := r.db.WithContext(ctx).Model(&OrphanedBlobRow{}).Order("created_at").Limit(limit).Pluck("storage_key", &out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *AttachmentRepo) ForgetBlobs(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("storage_key IN ?", keys).Delete(&OrphanedBlobRow{}).Error
+}
@@ -0,0 +1,151 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+)
+
+// CommentRepo works in the workspace of its context; every method runs in
+// a stamped transaction, so row-level security keeps it to that
+// workspace's comments.
+type CommentRepo struct {
+	db *gorm.DB
+}
+
+func NewCommentRepo(db *gorm.DB) *CommentRepo {
+	return &CommentRepo{db: db}
+}
+
+type CommentRow struct {
+	ID          string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	WorkspaceID string    `gorm:"column:workspace_id;type:uuid;not null"`
+	TaskID      string    `gorm:"column:task_id;type:uuid;not null"`
+	Author      string    `gorm:"column:author;type:text;not null;default:''"`
+	Body        string    `gorm:"column:body;type:text;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (CommentRow) TableName() string { return "public.task_comments" }
+
+func commentToDomain(r *CommentRow) *models.Comment {
+	return &models.Comment{
+		ID:        r.ID,
+		TaskID:    r.TaskID,
+		Author:    r.Author,
+		Body:      r.Body,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// commentsOn limits a query to taskID's comments in the current workspace.
+func commentsOn(tx *gorm.DB, taskID string) *gorm.DB {
+	return tx.Where("task_id = ? AND workspace_id = public.current_workspace()", taskID)
+}
+
+func (r *CommentRepo) Create(ctx context.Context, c *models.Comment) (*models.Comment, error) {
+	row := &CommentRow{WorkspaceID: tenant.WorkspaceID(ctx), TaskID: c.TaskID, Author: c.Author, Body: c.Body}
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return commentToDomain(row), nil
+}
+
+func (r *CommentRepo) GetByID(ctx context.Context, taskID, id string) (*models.Comment, error) {
+	var row CommentRow
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return commentsOn(tx, taskID).First(&row, "id = ?", id).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return commentToDomain(&row), nil
+}
+
+func (r *CommentRepo) List(ctx context.Context, taskID string, p repository.Pagination) ([]models.Comment, error) {
+	limit := repository.DefaultPageSize
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+
+	var rows []CommentRow
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return commentsOn(tx, taskID).
+			Order("created_at, id").
+			Limit(limit).
+			Offset(p.Offset).
+			Find(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Comment, len(rows))
+	for i := range rows {
+		out[i] = *commentToDomain(&rows[i])
+	}
+	return out, nil
+}
+
+func (r *CommentRepo) Count(ctx context.Context, taskID string) (int, error) {
+	var n int64
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return commentsOn(tx.Model(&CommentRow{}), taskID).Count(&n).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *CommentRepo) Update(ctx context.Context, c *models.Comment) (*models.Comment, error) {
+	var row CommentRow
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		res := commentsOn(tx.Model(&CommentRow{}), c.TaskID).
+			Where("id = ?", c.ID).
+			Update("body", c.Body)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrCommentNotFound
+		}
+		return commentsOn(tx, c.TaskID).First(&row, "id = ?", c.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commentToDomain(&row), nil
+}
+
+func (r *CommentRepo) Delete(ctx context.Context, taskID, id string) error {
+	return stamped(ctx, r.db, func(tx *gorm.DB) error {
+		res := commentsOn(tx, taskID).Where("id = ?", id).Delete(&CommentRow{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrCommentNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+package postgresgorm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DependencyRepo struct {
+	db *gorm.DB
+}
+
+func NewDependencyRepo(db *gorm.DB) *DependencyRepo {
+	return &DependencyRepo{db: db}
+}
+
+type DependencyRow struct {
+	TaskID    string    `gorm:"column:task_id;type:uuid;primaryKey"`
+	BlockerID string    `gorm:"column:blocker_id;type:uuid;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (DependencyRow) TableName() string { return "public.task_dependencies" }
+
+func (r *DependencyRepo) Add(ctx context.Context, taskID, blockerID string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// one writer per workspace graph, so two edges can't close a cycle together
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), hashtext(?))`, workspaceID).Error; err != nil {
+			return err
+		}
+
+		// walk blockerID's blockers; finding taskID among them means a cycle
+		var reach struct {
+			Cycle   bool
+			Visited int
+		}
+		err := tx.Raw(`
+			WITH RECURSIVE up (id) AS (
+				SELECT blocker_id FROM public.task_dependencies WHERE task_id = @blocker
+				UNION
+				SELECT d.blocker_id FROM public.task_dependencies d JOIN up ON d.task_id = up.id
+			), visited AS (
+				SELECT id FROM up LIMIT @limit
+			)
+			SELECT coalesce(bool_or(id = @task), false) AS cycle, count(*) AS visited FROM visited`,
+			sql.Named("blocker", blockerID), sql.Named("task", taskID), sql.Named("limit", repository.MaxDependencyVisits+1)).
+			Scan(&reach).Error
+		if err != nil {
+			return err
+		}
+		if reach.Cycle {
+			return models.ErrDependencyCycle
+		}
+		if reach.Visited > repository.MaxDependencyVisits {
+			return models.ErrDependencyGraphTooLarge
+		}
+
+		row := &DependencyRow{TaskID: taskID, BlockerID: blockerID}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error
+	})
+	if isForeignKeyViolation(err) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+func (r *DependencyRepo) Remove(ctx context.Context, taskID, blockerID string) error {
+	tx := r.db.WithContext(ctx).Where("task_id = ? AND blocker_id = ?", taskID, blockerID).Delete(&DependencyRow{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.ErrDependencyNotFound
+	}
+	return nil
+}
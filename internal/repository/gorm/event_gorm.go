@@ -0,0 +1,85 @@
+package postgresgorm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+)
+
+// TaskEventRepo reads history in a stamped transaction, so row-level
+// security keeps it to the workspace of its context.
+type TaskEventRepo struct {
+	db *gorm.DB
+}
+
+func NewTaskEventRepo(db *gorm.DB) *TaskEventRepo {
+	return &TaskEventRepo{db: db}
+}
+
+// TaskEventRow is read-only; rows are inserted by the tasks trigger.
+type TaskEventRow struct {
+	ID        int64     `gorm:"column:id;primaryKey"`
+	TaskID    string    `gorm:"column:task_id;type:uuid;not null"`
+	Action    string    `gorm:"column:action;type:text;not null"`
+	Changes   []byte    `gorm:"column:changes;type:jsonb;not null"`
+	Actor     *string   `gorm:"column:actor;type:text"`
+	RequestID *string   `gorm:"column:request_id;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (TaskEventRow) TableName() string { return "public.task_events" }
+
+func (r *TaskEventRepo) List(ctx context.Context, taskID string, p repository.Pagination) ([]models.TaskEvent, error) {
+	limit := repository.DefaultPageSize
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+
+	var rows []TaskEventRow
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return tx.Where("task_id = ? AND workspace_id = public.current_workspace()", taskID).
+			Order("id DESC").
+			Limit(limit).
+			Offset(p.Offset).
+			Find(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.TaskEvent, len(rows))
+	for i, row := range rows {
+		out[i] = models.TaskEvent{
+			ID:        row.ID,
+			TaskID:    row.TaskID,
+			Action:    models.EventAction(row.Action),
+			CreatedAt: row.CreatedAt,
+		}
+		if row.Actor != nil {
+			out[i].Actor = *row.Actor
+		}
+		if row.RequestID != nil {
+			out[i].RequestID = *row.RequestID
+		}
+		if err := json.Unmarshal(row.Changes, &out[i].Changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func (r *TaskEventRepo) Count(ctx context.Context, taskID string) (int, error) {
+	var n int64
+	err := stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return tx.Model(&TaskEventRow{}).Where("task_id = ? AND workspace_id = public.current_workspace()", taskID).Count(&n).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
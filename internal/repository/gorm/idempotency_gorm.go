@@ -0,0 +1,95 @@
+package postgresgorm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepo struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepo(db *gorm.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+type IdempotencyRow struct {
+	Key            string    `gorm:"column:key;type:text;primaryKey"`
+	RequestHash    string    `gorm:"column:request_hash;type:text;not null"`
+	StatusCode     *int      `gorm:"column:status_code"`
+	ResponseHeader []byte    `gorm:"column:response_header;type:jsonb"`
+	ResponseBody   []byte    `gorm:"column:response_body;type:bytea"`
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt      time.Time `gorm:"column:expires_at;not null"`
+}
+
+func (IdempotencyRow) TableName() string { return "public.idempotency_keys" }
+
+func (r *IdempotencyRepo) Reserve(ctx context.Context, rec *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	// Expired keys are taken over in place; live ones make the upsert a no-op
+	tx := r.db.WithContext(ctx).Exec(`
+		INSERT INTO public.idempotency_keys (key, request_hash, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		status_code = NULL,
+		response_header = NULL,
+		response_body = NULL,
+		created_at = now(),
+		expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()`,
+		rec.Key, rec.RequestHash, rec.ExpiresAt)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var row IdempotencyRow
+	if err := r.db.WithContext(ctx).First(&row, "key = ?", rec.Key).Error; err != nil {
+		return nil, err
+	}
+
+	existing := &repository.IdempotencyRecord{
+		Key:         row.Key,
+		RequestHash: row.RequestHash,
+		Body:        row.ResponseBody,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.StatusCode != nil {
+		existing.StatusCode = *row.StatusCode
+	}
+	if row.ResponseHeader != nil {
+		if err := json.Unmarshal(row.ResponseHeader, &existing.Header); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, rec *repository.IdempotencyRecord) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Model(&IdempotencyRow{}).Where("key = ?", rec.Key).Updates(map[string]any{
+		"status_code":     rec.StatusCode,
+		"response_header": header,
+		"response_body":   rec.Body,
+	}).Error
+}
+
+func (r *IdempotencyRepo) Release(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&IdempotencyRow{}).Error
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	tx := r.db.WithContext(ctx).Where("expires_at <= now()").Delete(&IdempotencyRow{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,98 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaskMemberRepo struct {
+	db *gorm.DB
+}
+
+func NewTaskMemberRepo(db *gorm.DB) *TaskMemberRepo {
+	return &TaskMemberRepo{db: db}
+}
+
+type TaskMemberRow struct {
+	TaskID    string    `gorm:"column:task_id;type:uuid;primaryKey"`
+	UserID    string    `gorm:"column:user_id;type:uuid;primaryKey"`
+	Role      string    `gorm:"column:role;type:text;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (TaskMemberRow) TableName() string { return "public.task_members" }
+
+func (r *TaskMemberRepo) List(ctx context.Context, taskID string) ([]models.TaskMember, error) {
+	out := []models.TaskMember{}
+	err := r.db.WithContext(ctx).
+		Table("public.task_members m").
+		Select("m.task_id, m.user_id, u.email, u.name, m.role, m.created_at").
+		Joins("JOIN public.users u ON u.id = m.user_id").
+		Where("m.task_id = ?", taskID).
+		Order("m.created_at, m.user_id").
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *TaskMemberRepo) Set(ctx context.Context, taskID, email string, role models.Role) (*models.TaskMember, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var user UserRow
+	err = r.db.WithContext(ctx).
+		Joins("JOIN public.workspace_members w ON w.user_id = public.users.id AND w.workspace_id = ?", workspaceID).
+		First(&user, "public.users.email = ?", email).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	row := &TaskMemberRow{TaskID: taskID, UserID: user.ID, Role: string(role)}
+	err = r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).
+		Create(row).Error
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &models.TaskMember{
+		TaskID:    row.TaskID,
+		UserID:    row.UserID,
+		Email:     user.Email,
+		Name:      user.Name,
+		Role:      role,
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
+
+func (r *TaskMemberRepo) Remove(ctx context.Context, taskID, userID string) error {
+	res := r.db.WithContext(ctx).Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&TaskMemberRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrMemberNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepo struct {
+	db *gorm.DB
+}
+
+func NewSessionRepo(db *gorm.DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+type SessionRow struct {
+	ID        string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    string     `gorm:"column:user_id;type:uuid;not null"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+}
+
+func (SessionRow) TableName() string { return "public.auth_sessions" }
+
+type RefreshTokenRow struct {
+	TokenHash string     `gorm:"column:token_hash;type:text;primaryKey"`
+	SessionID string     `gorm:"column:session_id;type:uuid;not null"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+}
+
+func (RefreshTokenRow) TableName() string { return "public.refresh_tokens" }
+
+func sessionToDomain(r *SessionRow) *models.Session {
+	return &models.Session{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		CreatedAt: r.CreatedAt,
+		ExpiresAt: r.ExpiresAt,
+		RevokedAt: r.RevokedAt,
+	}
+}
+
+func (r *SessionRepo) Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*models.Session, error) {
+	row := &SessionRow{UserID: userID, ExpiresAt: expiresAt}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(row).Error; err != nil {
+			return err
+		}
+		return tx.Create(&RefreshTokenRow{TokenHash: tokenHash, SessionID: row.ID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionToDomain(row), nil
+}
+
+func (r *SessionRepo) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	var row SessionRow
+	err := r.db.WithContext(ctx).First(&row, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionToDomain(&row), nil
+}
+
+func (r *SessionRepo) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	var (
+		out    *models.Session
+		reused bool
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// lock the token so that only one of two concurrent refreshes wins
+		var token RefreshTokenRow
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&token, "token_hash = ?", oldHash).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+		var session SessionRow
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, "id = ?", token.SessionID).Error; err != nil {
+			return err
+		}
+		if session.RevokedAt != nil || !session.ExpiresAt.After(time.Now()) {
+			return models.ErrRefreshTokenInvalid
+		}
+		if token.UsedAt != nil {
+			// someone is replaying an old token: the whole session is suspect
+			reused = true
+			return tx.Model(&session).Update("revoked_at", gorm.Expr("now()")).Error
+		}
+
+		if err := tx.Model(&token).Update("used_at", gorm.Expr("now()")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&RefreshTokenRow{TokenHash: newHash, SessionID: session.ID}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&session).Update("expires_at", expiresAt).Error; err != nil {
+			return err
+		}
+		session.ExpiresAt = expiresAt
+		out = sessionToDomain(&session)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	return out, nil
+}
+
+func (r *SessionRepo) Revoke(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Model(&SessionRow{}).Where("id = ?", id).
+		Update("revoked_at", gorm.Expr("COALESCE(revoked_at, now())"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) RevokeAll(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Model(&SessionRow{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", gorm.Expr("now()")).Error
+}
+
+func (r *SessionRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	tx := r.db.WithContext(ctx).Where("expires_at <= now() OR revoked_at IS NOT NULL").Delete(&SessionRow{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,156 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+)
+
+// StatusRepo keeps every workspace's status catalog to itself; each method
+// works in the workspace of its context.
+type StatusRepo struct {
+	db *gorm.DB
+}
+
+func NewStatusRepo(db *gorm.DB) *StatusRepo {
+	return &StatusRepo{db: db}
+}
+
+type StatusRow struct {
+	WorkspaceID string    `gorm:"column:workspace_id;type:uuid;primaryKey"`
+	Name        string    `gorm:"column:name;type:text;primaryKey"`
+	Category    string    `gorm:"column:category;type:text;not null"`
+	Position    int       `gorm:"column:position;not null;default:0"`
+	Color       string    `gorm:"column:color;type:text;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (StatusRow) TableName() string { return "public.task_statuses" }
+
+func statusToDomain(r *StatusRow) *models.StatusDefinition {
+	return &models.StatusDefinition{
+		Name:      models.TaskStatus(r.Name),
+		Category:  models.StatusCategory(r.Category),
+		Position:  r.Position,
+		Color:     r.Color,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// inWorkspace starts a query limited to the current workspace's statuses.
+func (r *StatusRepo) inWorkspace(ctx context.Context) (*gorm.DB, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID), nil
+}
+
+func (r *StatusRepo) Create(ctx context.Context, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &StatusRow{
+		WorkspaceID: workspaceID,
+		Name:        string(s.Name),
+		Category:    string(s.Category),
+		Position:    s.Position,
+		Color:       s.Color,
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return statusToDomain(row), nil
+}
+
+func (r *StatusRepo) GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var row StatusRow
+	err = q.First(&row, "name = ?", string(name)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrStatusNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return statusToDomain(&row), nil
+}
+
+func (r *StatusRepo) List(ctx context.Context) ([]models.StatusDefinition, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []StatusRow
+	if err := q.Order("position").Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]models.StatusDefinition, len(rows))
+	for i := range rows {
+		out[i] = *statusToDomain(&rows[i])
+	}
+	return out, nil
+}
+
+func (r *StatusRepo) Update(ctx context.Context, name models.TaskStatus, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{
+		"name":     string(s.Name),
+		"category": string(s.Category),
+		"position": s.Position,
+		"color":    s.Color,
+	}
+
+	tx := q.Model(&StatusRow{}).Where("name = ?", string(name)).Updates(data)
+	if tx.Error != nil {
+		if isUniqueViolation(tx.Error) {
+			return nil, models.ErrConflict
+		}
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, models.ErrStatusNotFound
+	}
+
+	return r.GetByName(ctx, s.Name)
+}
+
+func (r *StatusRepo) Delete(ctx context.Context, name models.TaskStatus) error {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx := q.Where("name = ?", string(name)).Delete(&StatusRow{})
+	if tx.Error != nil {
+		if isForeignKeyViolation(tx.Error) {
+			return models.ErrStatusInUse
+		}
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.ErrStatusNotFound
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TagRepo keeps every workspace's tags to itself; each method works in the
+// workspace of its context.
+type TagRepo struct {
+	db *gorm.DB
+}
+
+func NewTagRepo(db *gorm.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+type TagRow struct {
+	ID          string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	WorkspaceID string    `gorm:"column:workspace_id;type:uuid;not null;uniqueIndex:tags_workspace_id_name_key"`
+	Name        string    `gorm:"column:name;type:text;not null;uniqueIndex:tags_workspace_id_name_key"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (TagRow) TableName() string { return "public.tags" }
+
+type TaskTagRow struct {
+	TaskID string `gorm:"column:task_id;type:uuid;primaryKey"`
+	TagID  string `gorm:"column:tag_id;type:uuid;primaryKey"`
+}
+
+func (TaskTagRow) TableName() string { return "public.task_tags" }
+
+func tagToDomain(r *TagRow) *models.Tag {
+	return &models.Tag{
+		ID:        r.ID,
+		Name:      r.Name,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// inWorkspace starts a query limited to the current workspace's tags.
+func (r *TagRepo) inWorkspace(ctx context.Context) (*gorm.DB, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID), nil
+}
+
+func (r *TagRepo) Create(ctx context.Context, t *models.Tag) (*models.Tag, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &TagRow{WorkspaceID: workspaceID, Name: t.Name}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return tagToDomain(row), nil
+}
+
+func (r *TagRepo) GetByID(ctx context.Context, id string) (*models.Tag, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var row TagRow
+	err = q.First(&row, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tagToDomain(&row), nil
+}
+
+func (r *TagRepo) List(ctx context.Context) ([]models.Tag, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []TagRow
+	if err := q.Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Tag, len(rows))
+	for i := range rows {
+		out[i] = *tagToDomain(&rows[i])
+	}
+	return out, nil
+}
+
+func (r *TagRepo) Rename(ctx context.Context, id, name string) (*models.Tag, error) {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := q.Model(&TagRow{}).Where("id = ?", id).Update("name", name)
+	if tx.Error != nil {
+		if isUniqueViolation(tx.Error) {
+			return nil, models.ErrConflict
+		}
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, models.ErrTagNotFound
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *TagRepo) Delete(ctx context.Context, id string) error {
+	q, err := r.inWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx := q.Where("id = ?", id).Delete(&TagRow{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.ErrTagNotFound
+	}
+	return nil
+}
+
+// Attach only links tags of the current workspace; the caller checks the
+// task belongs to it.
+func (r *TagRepo) Attach(ctx context.Context, taskID, tagID string) error {
+	if _, err := r.GetByID(ctx, tagID); err != nil {
+		return err
+	}
+
+	row := &TaskTagRow{TaskID: taskID, TagID: tagID}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error
+	if isForeignKeyViolation(err) {
+		return models.ErrNotFound
+	}
+	return err
+}
+
+func (r *TagRepo) Detach(ctx context.Context, taskID, tagID string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx := r.db.WithContext(ctx).
+		Where("task_id = ? AND tag_id IN (SELECT id FROM public.tags WHERE id = ? AND workspace_id = ?)", taskID, tagID, workspaceID).
+		Delete(&TaskTagRow{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.ErrTagNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -2,10 +2,15 @@ package postgresgorm
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/Luc1808/TaskAPI/internal/audit"
 	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
 	"github.com/Luc1808/TaskAPI/pkg/models"
 	"gorm.io/gorm"
 )
@@ -20,12 +25,25 @@ func NewTaskRepo(db *gorm.DB) *TaskRepo {
 
 type TaskRow struct {
 	ID          string     `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	WorkspaceID string     `gorm:"column:workspace_id;type:uuid;not null"`
+	ParentID    *string    `gorm:"column:parent_id;type:uuid"`
 	Title       string     `gorm:"column:title;type:text;not null"`
 	Description string     `gorm:"column:description;type:text;not null;default:''"`
 	Status      string     `gorm:"column:status;type:text;not null"`
+	Priority    int        `gorm:"column:priority;type:integer;not null;default:1"`
 	DueAt       *time.Time `gorm:"column:due_at"`
+	StartedAt   *time.Time `gorm:"column:started_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at"`
 	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	Version     int        `gorm:"column:version;not null;default:1"`
+	ArchivedAt  *time.Time `gorm:"column:archived_at"`
+	CreatedBy   *string    `gorm:"column:created_by;type:uuid"`
+	DeletedAt   *time.Time `gorm:"column:deleted_at"`
+
+	// Read-only, selected on searches
+	Rank    float64 `gorm:"column:rank;->;-:migration"`
+	Snippet string  `gorm:"column:snippet;->;-:migration"`
 }
 
 func (TaskRow) TableName() string { return "public.tasks" }
@@ -33,25 +51,77 @@ func (TaskRow) TableName() string { return "public.tasks" }
 func toRow(t *models.Task) *TaskRow {
 	return &TaskRow{
 		ID:          t.ID,
+		ParentID:    t.ParentID,
 		Title:       t.Title,
 		Description: t.Description,
 		Status:      string(t.Status),
+		Priority:    t.Priority,
 		DueAt:       t.DueAt,
+		StartedAt:   t.StartedAt,
+		CompletedAt: t.CompletedAt,
 		CreatedAt:   t.CreatedAt,
 		UpdatedAt:   t.UpdatedAt,
+		Version:     t.Version,
+		ArchivedAt:  t.ArchivedAt,
+		CreatedBy:   t.CreatedBy,
+		DeletedAt:   t.DeletedAt,
 	}
 }
 
 func toDomain(r *TaskRow) *models.Task {
 	return &models.Task{
 		ID:          r.ID,
+		ParentID:    r.ParentID,
 		Title:       r.Title,
 		Description: r.Description,
 		Status:      models.TaskStatus(r.Status),
+		Priority:    r.Priority,
 		DueAt:       r.DueAt,
+		StartedAt:   r.StartedAt,
+		CompletedAt: r.CompletedAt,
 		CreatedAt:   r.CreatedAt,
 		UpdatedAt:   r.UpdatedAt,
+		Version:     r.Version,
+		ArchivedAt:  r.ArchivedAt,
+		CreatedBy:   r.CreatedBy,
+		DeletedAt:   r.DeletedAt,
+		Rank:        r.Rank,
+		Snippet:     r.Snippet,
+	}
+}
+
+// WithTx leans on gorm's Transaction, which nests through savepoints.
+func (r *TaskRepo) WithTx(ctx context.Context, fn func(tx repository.TaskRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&TaskRepo{db: tx})
+	})
+}
+
+// scoped runs fn in a stamped transaction. Every method goes through it,
+// reads included.
+func (r *TaskRepo) scoped(ctx context.Context, fn func(r *TaskRepo) error) error {
+	return stamped(ctx, r.db, func(tx *gorm.DB) error {
+		return fn(&TaskRepo{db: tx})
+	})
+}
+
+// stamped runs fn in a transaction stamped with the request's workspace,
+// actor and id. Row-level security keeps it to the workspace's tasks,
+// comments and history, and the task_events trigger records the actor and
+// id next to every change.
+func stamped(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
 	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`SELECT set_config('taskapi.workspace_id', ?, true), set_config('taskapi.actor', ?, true), set_config('taskapi.request_id', ?, true)`,
+			workspaceID, audit.Actor(ctx), audit.RequestID(ctx)).Error
+		if err != nil {
+			return err
+		}
+		return fn(tx)
+	})
 }
 
 func (r *TaskRepo) Create(ctx context.Context, t *models.Task) (*models.Task, error) {
@@ -60,79 +130,549 @@ func (r *TaskRepo) Create(ctx context.Context, t *models.Task) (*models.Task, er
 	}
 
 	row := toRow(t)
-	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+	row.WorkspaceID = tenant.WorkspaceID(ctx)
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		return r.db.WithContext(ctx).Create(row).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return toDomain(row), nil
+	out := toDomain(row)
+	out.Tags = []string{}
+	return out, nil
 }
 
 func (r *TaskRepo) GetByID(ctx context.Context, id string) (*models.Task, error) {
-	var row TaskRow
-	err := r.db.WithContext(ctx).First(&row, "id=?", id).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, models.ErrNotFound
-	}
+	tasks := make([]models.Task, 1)
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		var row TaskRow
+		err := r.db.WithContext(ctx).First(&row, "id = ? AND deleted_at IS NULL AND workspace_id = public.current_workspace()", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		tasks[0] = *toDomain(&row)
+		return r.hydrate(ctx, tasks)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return toDomain(&row), nil
+	return &tasks[0], nil
+}
+
+func (r *TaskRepo) Role(ctx context.Context, id, userID string) (models.Role, error) {
+	var role models.Role
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		return r.db.WithContext(ctx).
+			Raw("SELECT coalesce(public.task_role(id, ?), '') FROM public.tasks WHERE id = ? AND workspace_id = public.current_workspace()", userID, id).
+			Scan(&role).Error
+	})
+	return role, err
 }
 
 func (r *TaskRepo) List(ctx context.Context, f repository.ListFilter, p repository.Pagination) ([]models.Task, error) {
+	var out []models.Task
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		var err error
+		out, err = r.list(ctx, f, p)
+		return err
+	})
+	return out, err
+}
+
+func (r *TaskRepo) list(ctx context.Context, f repository.ListFilter, p repository.Pagination) ([]models.Task, error) {
 	q := r.db.WithContext(ctx).Model(&TaskRow{})
 
-	if f.Status != nil {
-		q = q.Where("status = ?", *f.Status)
-	}
+	q = applyFilter(q, f)
 	if f.Search != "" {
-		like := "%" + f.Search + "%"
-		q = q.Where("(title ILIKE ? OR description ILIKE ?)", like, like)
+		tsq := repository.PrefixTSQuery(f.Search)
+		q = q.Select("*, ts_rank_cd(search_vector, to_tsquery('"+repository.SearchConfig+"', ?)) AS rank, "+
+			"ts_headline('"+repository.SearchConfig+"', "+repository.HeadlineText+", to_tsquery('"+repository.SearchConfig+"', ?), '"+repository.HeadlineOptions+"') AS snippet",
+			tsq, tsq)
+	}
+
+	if p.After != nil {
+		q = q.Where("(created_at, id) > (?, ?)", p.After.CreatedAt, p.After.ID)
+	}
+	if p.Before != nil {
+		q = q.Where("(created_at, id) < (?, ?)", p.Before.CreatedAt, p.Before.ID)
 	}
 
-	limit := 50
+	limit := repository.DefaultPageSize
 	if p.Limit > 0 {
 		limit = p.Limit
 	}
+	offset := p.Offset
+	if p.After != nil || p.Before != nil {
+		offset = 0
+	}
+
+	// Same ordering as the postgres repo so keyset cursors work on both
+	switch {
+	case p.Before != nil:
+		q = q.Order("created_at DESC").Order("id DESC")
+	default:
+		for _, term := range repository.OrderTerms(f.Sort) {
+			q = q.Order(term)
+		}
+	}
 
 	var rows []TaskRow
-	if err := q.Order("created_at DESC").Limit(limit).Offset(p.Offset).Find(&rows).Error; err != nil {
+	if err := q.Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, err
 	}
+	if p.Before != nil {
+		slices.Reverse(rows)
+	}
 
 	out := make([]models.Task, len(rows))
 	for i := range rows {
 		out[i] = *toDomain(&rows[i])
 	}
+	if err := r.hydrate(ctx, out); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
+// hydrate fills in the fields that live outside public.tasks.
+func (r *TaskRepo) hydrate(ctx context.Context, tasks []models.Task) error {
+	if err := r.loadTags(ctx, tasks); err != nil {
+		return err
+	}
+	if err := r.loadProgress(ctx, tasks); err != nil {
+		return err
+	}
+	if err := r.loadCommentCounts(ctx, tasks); err != nil {
+		return err
+	}
+	return r.loadBlocked(ctx, tasks)
+}
+
+// loadCommentCounts counts the comments on every task with one round trip.
+func (r *TaskRepo) loadCommentCounts(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	var rows []struct {
+		TaskID string
+		N      int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&CommentRow{}).
+		Select("task_id, count(*) AS n").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		tasks[byID[row.TaskID]].CommentCount = row.N
+	}
+
+	return nil
+}
+
+// loadBlocked flags tasks that still have at least one blocker that isn't closed.
+func (r *TaskRepo) loadBlocked(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	var blocked []string
+	err := r.db.WithContext(ctx).
+		Table("public.task_dependencies d").
+		Distinct("d.task_id").
+		Joins("JOIN public.tasks b ON b.id = d.blocker_id").
+		Where("d.task_id IN ? AND b.deleted_at IS NULL AND b.status NOT IN ("+repository.ClosedStatuses+")", ids).
+		Pluck("d.task_id", &blocked).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range blocked {
+		tasks[byID[id]].Blocked = true
+	}
+
+	return nil
+}
+
+// loadProgress counts direct children (and how many are closed) per task.
+func (r *TaskRepo) loadProgress(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	var rows []struct {
+		ParentID string
+		Total    int
+		Done     int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&TaskRow{}).
+		Select("parent_id, count(*) AS total, count(*) FILTER (WHERE status IN ("+repository.ClosedStatuses+")) AS done").
+		Where("parent_id IN ? AND deleted_at IS NULL", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		tasks[byID[row.ParentID]].Progress = &models.Progress{Done: row.Done, Total: row.Total}
+	}
+
+	return nil
+}
+
+// loadTags fills in the tag names of every task with one round trip.
+func (r *TaskRepo) loadTags(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+		tasks[i].Tags = []string{}
+	}
+
+	var rows []struct {
+		TaskID string
+		Name   string
+	}
+	err := r.db.WithContext(ctx).
+		Table("public.task_tags tt").
+		Select("tt.task_id, g.name").
+		Joins("JOIN public.tags g ON g.id = tt.tag_id").
+		Where("tt.task_id IN ?", ids).
+		Order("g.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		i := byID[row.TaskID]
+		tasks[i].Tags = append(tasks[i].Tags, row.Name)
+	}
+
+	return nil
+}
+
+func (r *TaskRepo) Count(ctx context.Context, f repository.ListFilter) (int, error) {
+	var n int64
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		return applyFilter(r.db.WithContext(ctx).Model(&TaskRow{}), f).Count(&n).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func applyFilter(q *gorm.DB, f repository.ListFilter) *gorm.DB {
+	q = q.Where("workspace_id = public.current_workspace()")
+	if f.Trashed {
+		q = q.Where("deleted_at IS NOT NULL")
+	} else {
+		q = q.Where("deleted_at IS NULL")
+	}
+	switch f.Archived {
+	case repository.ArchivedExclude:
+		q = q.Where("archived_at IS NULL")
+	case repository.ArchivedOnly:
+		q = q.Where("archived_at IS NOT NULL")
+	}
+	if f.ParentID != nil {
+		q = q.Where("parent_id = ?", *f.ParentID)
+	}
+	if f.BlockersOf != nil {
+		q = q.Where("id IN (SELECT blocker_id FROM public.task_dependencies WHERE task_id = ?)", *f.BlockersOf)
+	}
+	if f.Status != nil {
+		q = q.Where("status = ?", *f.Status)
+	}
+	if f.Priority != nil {
+		q = q.Where("priority = ?", *f.Priority)
+	}
+	if f.DueBefore != nil {
+		q = q.Where("due_at < ?", *f.DueBefore)
+	}
+	if f.DueAfter != nil {
+		q = q.Where("due_at > ?", *f.DueAfter)
+	}
+	if f.Overdue {
+		q = q.Where("due_at < now() AND status NOT IN (" + repository.ClosedStatuses + ")")
+	}
+	if len(f.Tags) > 0 || len(f.TagsAny) > 0 || len(f.TagsNone) > 0 {
+		tagged := func(names []string) *gorm.DB {
+			return q.Session(&gorm.Session{NewDB: true}).
+				Table("public.task_tags tt").
+				Select("tt.task_id").
+				Joins("JOIN public.tags g ON g.id = tt.tag_id").
+				Where("g.name IN ?", names)
+		}
+		if len(f.Tags) > 0 {
+			q = q.Where("id IN (?)", tagged(f.Tags).Group("tt.task_id").Having("count(*) = ?", len(f.Tags)))
+		}
+		if len(f.TagsAny) > 0 {
+			q = q.Where("id IN (?)", tagged(f.TagsAny))
+		}
+		if len(f.TagsNone) > 0 {
+			q = q.Where("id NOT IN (?)", tagged(f.TagsNone))
+		}
+	}
+	if f.Search != "" {
+		q = q.Where("search_vector @@ to_tsquery('"+repository.SearchConfig+"', ?)", repository.PrefixTSQuery(f.Search))
+	}
+	if f.VisibleTo != "" {
+		minRole := f.MinRole
+		if minRole == "" {
+			minRole = models.RoleViewer
+		}
+		q = q.Where("id IN ("+repository.VisibleTaskIDs("user_id = ? AND role IN ?")+")", f.VisibleTo, models.RolesIncluding(minRole))
+	}
+
+	return q
+}
+
 func (r *TaskRepo) Update(ctx context.Context, t *models.Task) (*models.Task, error) {
 	if err := t.Validate(); err != nil {
 		return nil, err
 	}
 
 	data := map[string]any{
-		"title":       t.Title,
-		"description": t.Description,
-		"status":      string(t.Status),
-		"due_at":      t.DueAt,
+		"title":        t.Title,
+		"description":  t.Description,
+		"status":       string(t.Status),
+		"priority":     t.Priority,
+		"due_at":       t.DueAt,
+		"parent_id":    t.ParentID,
+		"started_at":   t.StartedAt,
+		"completed_at": t.CompletedAt,
+		"archived_at":  t.ArchivedAt,
+		"version":      gorm.Expr("version + 1"),
 	}
 
-	tx := r.db.WithContext(ctx).Model(&TaskRow{}).Where("id = ?", t.ID).Updates(data)
-	if tx.Error != nil {
-		return nil, tx.Error
+	var out *models.Task
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		tx := r.db.WithContext(ctx).Model(&TaskRow{}).
+			Where("id = ? AND version = ? AND deleted_at IS NULL AND workspace_id = public.current_workspace()", t.ID, t.Version).
+			Updates(data)
+		if tx.Error != nil {
+			return tx.Error
+		}
+		if tx.RowsAffected == 0 {
+			return r.missOrConflict(ctx, t.ID)
+		}
+
+		var err error
+		out, err = r.GetByID(ctx, t.ID)
+		return err
+	})
+	return out, err
+}
+
+// missOrConflict tells a missing task apart from a stale version after a
+// conditional write matched no rows.
+func (r *TaskRepo) missOrConflict(ctx context.Context, id string) error {
+	var n int64
+	if err := r.db.WithContext(ctx).Model(&TaskRow{}).Where("id = ? AND deleted_at IS NULL AND workspace_id = public.current_workspace()", id).Count(&n).Error; err != nil {
+		return err
 	}
-	if tx.RowsAffected == 0 {
-		return nil, models.ErrNotFound
+	if n > 0 {
+		return models.ErrVersionConflict
+	}
+	return models.ErrNotFound
+}
+
+func (r *TaskRepo) Delete(ctx context.Context, id string, version int) error {
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		var n int
+		err := r.db.WithContext(ctx).Raw(`
+		WITH trashed AS (
+			UPDATE public.tasks SET deleted_at = now()
+			WHERE id = @id AND (@version = 0 OR version = @version) AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			RETURNING id
+		), orphaned AS (
+			UPDATE public.tasks SET parent_id = NULL, updated_at = now(), version = version + 1
+			WHERE parent_id IN (SELECT id FROM trashed) AND deleted_at IS NULL
+		)
+		SELECT count(*) FROM trashed`, sql.Named("id", id), sql.Named("version", version)).Scan(&n).Error
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return r.missOrConflict(ctx, id)
+		}
+		return nil
+	})
+}
+
+func (r *TaskRepo) Subtree(ctx context.Context, id string) ([]models.Task, error) {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 1 AS depth FROM public.tasks WHERE parent_id = ? AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NULL
+		)
+		SELECT public.tasks.*
+		FROM public.tasks
+		JOIN (SELECT id, min(depth) AS depth FROM sub GROUP BY id) d USING (id)
+		ORDER BY d.depth, created_at, id`, repository.MaxTreeDepth)
+
+	var out []models.Task
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		var rows []TaskRow
+		if err := r.db.WithContext(ctx).Raw(q, id).Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		out = make([]models.Task, len(rows))
+		for i := range rows {
+			out[i] = *toDomain(&rows[i])
+		}
+		return r.hydrate(ctx, out)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return out, nil
+}
+
+func (r *TaskRepo) DeleteTree(ctx context.Context, id string, version int) error {
+	// now() is fixed per transaction, so the whole tree shares one deleted_at
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 0 AS depth FROM public.tasks
+			WHERE id = @id AND (@version = 0 OR version = @version) AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NULL
+		)
+		UPDATE public.tasks SET deleted_at = now() WHERE id IN (SELECT id FROM sub)`, repository.MaxTreeDepth)
+
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		err := r.execOne(ctx, q, sql.Named("id", id), sql.Named("version", version))
+		if errors.Is(err, models.ErrNotFound) {
+			return r.missOrConflict(ctx, id)
+		}
+		return err
+	})
+}
+
+func (r *TaskRepo) Restore(ctx context.Context, id string) (*models.Task, error) {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE root AS (
+			SELECT id, deleted_at FROM public.tasks WHERE id = @id AND deleted_at IS NOT NULL AND workspace_id = public.current_workspace()
+		), sub AS (
+			SELECT id, 0 AS depth FROM root
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at = (SELECT deleted_at FROM root)
+		)
+		UPDATE public.tasks
+		SET deleted_at = NULL,
+		updated_at = now(),
+		version = version + 1,
+		parent_id = CASE
+			WHEN id = @id AND parent_id IN (SELECT id FROM public.tasks WHERE deleted_at IS NOT NULL) THEN NULL
+			ELSE parent_id
+		END
+		WHERE id IN (SELECT id FROM sub)`, repository.MaxTreeDepth)
+
+	var out *models.Task
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		if err := r.execOne(ctx, q, sql.Named("id", id)); err != nil {
+			return err
+		}
+		var err error
+		out, err = r.GetByID(ctx, id)
+		return err
+	})
+	return out, err
+}
+
+func (r *TaskRepo) Purge(ctx context.Context, id string) error {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 0 AS depth FROM public.tasks WHERE id = ? AND deleted_at IS NOT NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NOT NULL
+		)
+		DELETE FROM public.tasks WHERE id IN (SELECT id FROM sub)`, repository.MaxTreeDepth)
+
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		return r.execOne(ctx, q, id)
+	})
+}
 
-	return r.GetByID(ctx, t.ID)
+func (r *TaskRepo) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		tx := r.db.WithContext(ctx).Where("deleted_at < ? AND workspace_id = public.current_workspace()", before).Delete(&TaskRow{})
+		n = tx.RowsAffected
+		return tx.Error
+	})
+	return n, err
+}
+
+func (r *TaskRepo) ArchiveClosed(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		tx := r.db.WithContext(ctx).Model(&TaskRow{}).
+			Where("archived_at IS NULL AND deleted_at IS NULL AND workspace_id = public.current_workspace()").
+			Where("completed_at < ? AND status IN ("+repository.ClosedStatuses+")", before).
+			Updates(map[string]any{
+				"archived_at": gorm.Expr("now()"),
+				"version":     gorm.Expr("version + 1"),
+			})
+		n = tx.RowsAffected
+		return tx.Error
+	})
+	return n, err
 }
 
-func (r *TaskRepo) Delete(ctx context.Context, id string) error {
-	tx := r.db.WithContext(ctx).Where("id = ?", id).Delete(&TaskRow{})
+// execOne runs q and reports models.ErrNotFound if it touched no rows.
+func (r *TaskRepo) execOne(ctx context.Context, q string, args ...any) error {
+	tx := r.db.WithContext(ctx).Exec(q, args...)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -0,0 +1,121 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRepo struct {
+	db *gorm.DB
+}
+
+func NewUserRepo(db *gorm.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+type UserRow struct {
+	ID           string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	Email        string    `gorm:"column:email;type:text;not null;unique"`
+	Name         string    `gorm:"column:name;type:text;not null;default:''"`
+	PasswordHash string    `gorm:"column:password_hash;type:text;not null"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserRow) TableName() string { return "public.users" }
+
+type ResetTokenRow struct {
+	TokenHash string    `gorm:"column:token_hash;type:text;primaryKey"`
+	UserID    string    `gorm:"column:user_id;type:uuid;not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ResetTokenRow) TableName() string { return "public.password_reset_tokens" }
+
+func userToDomain(r *UserRow) *models.User {
+	return &models.User{
+		ID:           r.ID,
+		Email:        r.Email,
+		Name:         r.Name,
+		PasswordHash: r.PasswordHash,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+func (r *UserRepo) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	row := &UserRow{Email: u.Email, Name: u.Name, PasswordHash: u.PasswordHash}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return userToDomain(row), nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getBy(ctx, "id = ?", id)
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getBy(ctx, "email = ?", email)
+}
+
+func (r *UserRepo) getBy(ctx context.Context, cond string, value string) (*models.User, error) {
+	var row UserRow
+	err := r.db.WithContext(ctx).First(&row, cond, value).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return userToDomain(&row), nil
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, id, hash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&UserRow{}).Where("id = ?", id).Update("password_hash", hash)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrUserNotFound
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&ResetTokenRow{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&SessionRow{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", gorm.Expr("now()")).Error
+	})
+}
+
+func (r *UserRepo) CreateResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Create(&ResetTokenRow{TokenHash: tokenHash, UserID: userID, ExpiresAt: expiresAt}).Error
+}
+
+func (r *UserRepo) ConsumeResetToken(ctx context.Context, tokenHash string) (string, error) {
+	var rows []ResetTokenRow
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "user_id"}}}).
+		Where("token_hash = ? AND expires_at > now()", tokenHash).
+		Delete(&rows).Error
+	if err != nil {
+		return "", err
+	}
+	if len(rows) == 0 {
+		return "", models.ErrResetTokenInvalid
+	}
+
+	return rows[0].UserID, nil
+}
@@ -0,0 +1,170 @@
+package postgresgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WorkspaceRepo struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepo(db *gorm.DB) *WorkspaceRepo {
+	return &WorkspaceRepo{db: db}
+}
+
+type WorkspaceRow struct {
+	ID        string    `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey"`
+	Slug      string    `gorm:"column:slug;type:text;not null;unique"`
+	Name      string    `gorm:"column:name;type:text;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (WorkspaceRow) TableName() string { return "public.workspaces" }
+
+type WorkspaceMemberRow struct {
+	WorkspaceID string    `gorm:"column:workspace_id;type:uuid;primaryKey"`
+	UserID      string    `gorm:"column:user_id;type:uuid;primaryKey"`
+	Role        string    `gorm:"column:role;type:text;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (WorkspaceMemberRow) TableName() string { return "public.workspace_members" }
+
+func (r *WorkspaceRepo) Create(ctx context.Context, w *models.Workspace, userID string) (*models.Workspace, error) {
+	row := &WorkspaceRow{Slug: w.Slug, Name: w.Name}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(row).Error; err != nil {
+			return err
+		}
+		return tx.Create(&WorkspaceMemberRow{WorkspaceID: row.ID, UserID: userID, Role: string(models.WorkspaceAdmin)}).Error
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &models.Workspace{
+		ID:        row.ID,
+		Slug:      row.Slug,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt,
+		Role:      models.WorkspaceAdmin,
+	}, nil
+}
+
+// memberships starts a query over userID's workspaces with their role.
+func (r *WorkspaceRepo) memberships(ctx context.Context, userID string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("public.workspace_members m").
+		Select("w.id, w.slug, w.name, w.created_at, m.role").
+		Joins("JOIN public.workspaces w ON w.id = m.workspace_id").
+		Where("m.user_id = ?", userID)
+}
+
+func (r *WorkspaceRepo) ForUser(ctx context.Context, userID string) ([]models.Workspace, error) {
+	out := []models.Workspace{}
+	if err := r.memberships(ctx, userID).Order("m.created_at, w.id").Scan(&out).Error; err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) Membership(ctx context.Context, slug, userID string) (*models.Workspace, error) {
+	var out []models.Workspace
+	if err := r.memberships(ctx, userID).Where("w.slug = ?", slug).Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, models.ErrWorkspaceNotFound
+	}
+
+	return &out[0], nil
+}
+
+func (r *WorkspaceRepo) IDs(ctx context.Context) ([]string, error) {
+	out := []string{}
+	if err := r.db.WithContext(ctx).Model(&WorkspaceRow{}).Order("created_at, id").Pluck("id", &out).Error; err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) Members(ctx context.Context, workspaceID string) ([]models.WorkspaceUser, error) {
+	out := []models.WorkspaceUser{}
+	err := r.db.WithContext(ctx).
+		Table("public.workspace_members m").
+		Select("m.workspace_id, m.user_id, u.email, u.name, m.role, m.created_at").
+		Joins("JOIN public.users u ON u.id = m.user_id").
+		Where("m.workspace_id = ?", workspaceID).
+		Order("m.created_at, m.user_id").
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) SetMember(ctx context.Context, workspaceID, email string, role models.WorkspaceRole) (*models.WorkspaceUser, error) {
+	var user UserRow
+	err := r.db.WithContext(ctx).First(&user, "email = ?", email).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	row := &WorkspaceMemberRow{WorkspaceID: workspaceID, UserID: user.ID, Role: string(role)}
+	err = r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).
+		Create(row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WorkspaceUser{
+		WorkspaceID: row.WorkspaceID,
+		UserID:      row.UserID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        role,
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
+
+func (r *WorkspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&WorkspaceMemberRow{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return models.ErrWorkspaceUserNotFound
+		}
+
+		// tasks are behind row-level security, so name the workspace first
+		if err := tx.Exec("SELECT set_config('taskapi.workspace_id', ?, true)", workspaceID).Error; err != nil {
+			return err
+		}
+		err := tx.Where("user_id = ? AND task_id IN (SELECT id FROM public.tasks WHERE workspace_id = ?)", userID, workspaceID).
+			Delete(&TaskMemberRow{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&APIKeyRow{}).Error
+	})
+}
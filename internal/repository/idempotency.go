@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord is a request seen under an Idempotency-Key and, once
+// handled, the response it produced.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// StatusCode is 0 while the original request is still in flight
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	ExpiresAt  time.Time
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims rec.Key for a new request. If a live record already
+	// holds the key it is returned instead and nothing is written;
+	// expired records are taken over.
+	Reserve(ctx context.Context, rec *IdempotencyRecord) (*IdempotencyRecord, error)
+	// Complete stores the response for a reserved key
+	Complete(ctx context.Context, rec *IdempotencyRecord) error
+	// Release forgets a reserved key so the request can be retried
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
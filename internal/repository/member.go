@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// TaskMemberRepository manages the roles granted directly on a task.
+// Effective roles, which include those inherited from ancestors, come from
+// TaskRepository.Role.
+type TaskMemberRepository interface {
+	// List is ordered by when the member was added
+	List(ctx context.Context, taskID string) ([]models.TaskMember, error)
+	// Set grants the user with this email a role, replacing any role they
+	// had; models.ErrUserNotFound if there's no such user in the current
+	// workspace
+	Set(ctx context.Context, taskID, email string, role models.Role) (*models.TaskMember, error)
+	Remove(ctx context.Context, taskID, userID string) error
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const apiKeyColumns = "id, user_id, workspace_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at"
+
+type APIKeyRepo struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepo(db *sqlx.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+// apiKeyRow mirrors models.APIKey with scopes as stored
+type apiKeyRow struct {
+	ID          string     `db:"id"`
+	UserID      string     `db:"user_id"`
+	WorkspaceID string     `db:"workspace_id"`
+	Name        string     `db:"name"`
+	Prefix      string     `db:"prefix"`
+	KeyHash     string     `db:"key_hash"`
+	Scopes      string     `db:"scopes"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	LastUsedAt  *time.Time `db:"last_used_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+func (r apiKeyRow) toModel() models.APIKey {
+	return models.APIKey{
+		ID:          r.ID,
+		UserID:      r.UserID,
+		WorkspaceID: r.WorkspaceID,
+		Name:        r.Name,
+		Prefix:      r.Prefix,
+		KeyHash:     r.KeyHash,
+		Scopes:      strings.Fields(r.Scopes),
+		ExpiresAt:   r.ExpiresAt,
+		LastUsedAt:  r.LastUsedAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, k *models.APIKey) (*models.APIKey, error) {
+	const q = `
+		INSERT INTO public.api_keys (user_id, workspace_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+		`
+	scopes := strings.Join(k.Scopes, " ")
+	if err := r.db.QueryRowContext(ctx, q, k.UserID, k.WorkspaceID, k.Name, k.Prefix, k.KeyHash, scopes, k.ExpiresAt).Scan(&k.ID, &k.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context, userID string) ([]models.APIKey, error) {
+	const q = `
+		SELECT ` + apiKeyColumns + `
+		FROM public.api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id;
+		`
+	var rows []apiKeyRow
+	if err := r.db.SelectContext(ctx, &rows, q, userID); err != nil {
+		return nil, err
+	}
+
+	out := make([]models.APIKey, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, row.toModel())
+	}
+	return out, nil
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	const q = `SELECT ` + apiKeyColumns + ` FROM public.api_keys WHERE key_hash = $1;`
+
+	var row apiKeyRow
+	if err := r.db.GetContext(ctx, &row, q, keyHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	out := row.toModel()
+	return &out, nil
+}
+
+func (r *APIKeyRepo) Delete(ctx context.Context, userID, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.api_keys WHERE id = $1 AND user_id = $2;`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepo) Touch(ctx context.Context, id string) error {
+	const q = `
+		UPDATE public.api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+		`
+	_, err := r.db.ExecContext(ctx, q, id)
+	return err
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const attachmentColumns = "id, task_id, filename, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at"
+
+type AttachmentRepo struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepo(db *sqlx.DB) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+func (r *AttachmentRepo) Create(ctx context.Context, a *models.Attachment) (*models.Attachment, error) {
+	const q = `
+		INSERT INTO public.task_attachments (task_id, filename, content_type, size_bytes, sha256, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+		`
+	if err := r.db.QueryRowContext(ctx, q, a.TaskID, a.Filename, a.ContentType, a.Size, a.SHA256,
+		a.StorageKey, a.UploadedBy).Scan(&a.ID, &a.CreatedAt); err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (r *AttachmentRepo) GetByID(ctx context.Context, taskID, id string) (*models.Attachment, error) {
+	const q = `SELECT ` + attachmentColumns + ` FROM public.task_attachments WHERE id = $1 AND task_id = $2;`
+
+	var out models.Attachment
+	if err := r.db.GetContext(ctx, &out, q, id, taskID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *AttachmentRepo) List(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	const q = `
+		SELECT ` + attachmentColumns + `
+		FROM public.task_attachments
+		WHERE task_id = $1
+		ORDER BY created_at, id;
+		`
+	out := []models.Attachment{}
+	if err := r.db.SelectContext(ctx, &out, q, taskID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *AttachmentRepo) Delete(ctx context.Context, taskID, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.task_attachments WHERE id = $1 AND task_id = $2;`, id, taskID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *AttachmentRepo) OrphanedBlobs(ctx context.Context, limit int) ([]string, error) {
+	const q = `SELECT storage_key FROM public.orphaned_blobs ORDER BY created_at LIMIT $1;`
+
+	out := []string{}
+	if err := r.db.SelectContext(ctx, &out, q, limit); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *AttachmentRepo) ForgetBlobs(ctx context.Context, keys []string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM public.orphaned_blobs WHERE storage_key = ANY($1::text[]);`, keys)
+	return err
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const commentColumns = "id, task_id, author, body, created_at, updated_at"
+
+// CommentRepo works in the workspace of its context; every method runs in
+// a stamped transaction, so row-level security keeps it to that
+// workspace's comments.
+type CommentRepo struct {
+	db *sqlx.DB
+}
+
+func NewCommentRepo(db *sqlx.DB) *CommentRepo {
+	return &CommentRepo{db: db}
+}
+
+func (r *CommentRepo) Create(ctx context.Context, c *models.Comment) (*models.Comment, error) {
+	const q = `
+		INSERT INTO public.task_comments (workspace_id, task_id, author, body)
+		VALUES (public.current_workspace(), $1, $2, $3)
+		RETURNING id, created_at, updated_at;
+		`
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.QueryRowContext(ctx, q, c.TaskID, c.Author, c.Body).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r *CommentRepo) GetByID(ctx context.Context, taskID, id string) (*models.Comment, error) {
+	const q = `
+		SELECT ` + commentColumns + ` FROM public.task_comments
+		WHERE id = $1 AND task_id = $2 AND workspace_id = public.current_workspace();
+		`
+
+	var out models.Comment
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &out, q, id, taskID)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *CommentRepo) List(ctx context.Context, taskID string, p repository.Pagination) ([]models.Comment, error) {
+	const q = `
+		SELECT ` + commentColumns + `
+		FROM public.task_comments
+		WHERE task_id = $1 AND workspace_id = public.current_workspace()
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3;
+		`
+	limit := repository.DefaultPageSize
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+
+	out := []models.Comment{}
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &out, q, taskID, limit, p.Offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *CommentRepo) Count(ctx context.Context, taskID string) (int, error) {
+	const q = `SELECT count(*) FROM public.task_comments WHERE task_id = $1 AND workspace_id = public.current_workspace();`
+
+	var n int
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &n, q, taskID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (r *CommentRepo) Update(ctx context.Context, c *models.Comment) (*models.Comment, error) {
+	const q = `
+		UPDATE public.task_comments
+		SET body = $1
+		WHERE id = $2 AND task_id = $3 AND workspace_id = public.current_workspace()
+		RETURNING ` + commentColumns + `;
+		`
+	var out models.Comment
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.QueryRowxContext(ctx, q, c.Body, c.ID, c.TaskID).StructScan(&out)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *CommentRepo) Delete(ctx context.Context, taskID, id string) error {
+	const q = `DELETE FROM public.task_comments WHERE id = $1 AND task_id = $2 AND workspace_id = public.current_workspace();`
+
+	var n int64
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, q, id, taskID)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrCommentNotFound
+	}
+
+	return nil
+}
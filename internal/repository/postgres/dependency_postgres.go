@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type DependencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewDependencyRepo(db *sqlx.DB) *DependencyRepo {
+	return &DependencyRepo{db: db}
+}
+
+func (r *DependencyRepo) Add(ctx context.Context, taskID, blockerID string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	// one writer per workspace graph, so two edges can't close a cycle together
+	const lock = `SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), hashtext($1));`
+	if _, err := tx.ExecContext(ctx, lock, workspaceID); err != nil {
+		return err
+	}
+
+	// walk blockerID's blockers; finding taskID among them means a cycle
+	const reach = `
+		WITH RECURSIVE up (id) AS (
+			SELECT blocker_id FROM public.task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.blocker_id FROM public.task_dependencies d JOIN up ON d.task_id = up.id
+		), visited AS (
+			SELECT id FROM up LIMIT $3
+		)
+		SELECT coalesce(bool_or(id = $2), false), count(*) FROM visited;
+		`
+	var (
+		cycle   bool
+		visited int
+	)
+	if err := tx.QueryRowContext(ctx, reach, blockerID, taskID, repository.MaxDependencyVisits+1).Scan(&cycle, &visited); err != nil {
+		return err
+	}
+	if cycle {
+		return models.ErrDependencyCycle
+	}
+	if visited > repository.MaxDependencyVisits {
+		return models.ErrDependencyGraphTooLarge
+	}
+
+	const q = `
+		INSERT INTO public.task_dependencies (task_id, blocker_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING;
+		`
+	if _, err := tx.ExecContext(ctx, q, taskID, blockerID); err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrNotFound
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *DependencyRepo) Remove(ctx context.Context, taskID, blockerID string) error {
+	const q = `DELETE FROM public.task_dependencies WHERE task_id = $1 AND blocker_id = $2;`
+	res, err := r.db.ExecContext(ctx, q, taskID, blockerID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrDependencyNotFound
+	}
+
+	return nil
+}
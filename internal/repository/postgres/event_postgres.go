@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// TaskEventRepo reads history in a stamped transaction, so row-level
+// security keeps it to the workspace of its context.
+type TaskEventRepo struct {
+	db *sqlx.DB
+}
+
+func NewTaskEventRepo(db *sqlx.DB) *TaskEventRepo {
+	return &TaskEventRepo{db: db}
+}
+
+type eventRow struct {
+	ID        int64     `db:"id"`
+	TaskID    string    `db:"task_id"`
+	Action    string    `db:"action"`
+	Changes   []byte    `db:"changes"`
+	Actor     string    `db:"actor"`
+	RequestID string    `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (r *TaskEventRepo) List(ctx context.Context, taskID string, p repository.Pagination) ([]models.TaskEvent, error) {
+	const q = `
+		SELECT id, task_id, action, changes, coalesce(actor, '') AS actor,
+		coalesce(request_id, '') AS request_id, created_at
+		FROM public.task_events
+		WHERE task_id = $1 AND workspace_id = public.current_workspace()
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3;
+		`
+	limit := repository.DefaultPageSize
+	if p.Limit > 0 {
+		limit = p.Limit
+	}
+
+	var rows []eventRow
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &rows, q, taskID, limit, p.Offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]models.TaskEvent, len(rows))
+	for i, row := range rows {
+		out[i] = models.TaskEvent{
+			ID:        row.ID,
+			TaskID:    row.TaskID,
+			Action:    models.EventAction(row.Action),
+			Actor:     row.Actor,
+			RequestID: row.RequestID,
+			CreatedAt: row.CreatedAt,
+		}
+		if err := json.Unmarshal(row.Changes, &out[i].Changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func (r *TaskEventRepo) Count(ctx context.Context, taskID string) (int, error) {
+	const q = `SELECT count(*) FROM public.task_events WHERE task_id = $1 AND workspace_id = public.current_workspace();`
+
+	var n int
+	err := inWorkspace(ctx, r.db, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &n, q, taskID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
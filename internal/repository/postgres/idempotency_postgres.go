@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepo(db *sqlx.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+func (r *IdempotencyRepo) Reserve(ctx context.Context, rec *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	const claim = `
+		INSERT INTO public.idempotency_keys (key, request_hash, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		status_code = NULL,
+		response_header = NULL,
+		response_body = NULL,
+		created_at = now(),
+		expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+		RETURNING key;
+		`
+	var key string
+	err := r.db.QueryRowContext(ctx, claim, rec.Key, rec.RequestHash, rec.ExpiresAt).Scan(&key)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	const q = `
+		SELECT request_hash, status_code, response_header, response_body, expires_at
+		FROM public.idempotency_keys
+		WHERE key = $1;
+		`
+	var (
+		existing = repository.IdempotencyRecord{Key: rec.Key}
+		status   sql.NullInt32
+		header   []byte
+	)
+	if err := r.db.QueryRowContext(ctx, q, rec.Key).Scan(
+		&existing.RequestHash, &status, &header, &existing.Body, &existing.ExpiresAt); err != nil {
+		return nil, err
+	}
+	existing.StatusCode = int(status.Int32)
+	if header != nil {
+		if err := json.Unmarshal(header, &existing.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	return &existing, nil
+}
+
+func (r *IdempotencyRepo) Complete(ctx context.Context, rec *repository.IdempotencyRecord) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		UPDATE public.idempotency_keys
+		SET status_code = $2, response_header = $3, response_body = $4
+		WHERE key = $1;
+		`
+	_, err = r.db.ExecContext(ctx, q, rec.Key, rec.StatusCode, header, rec.Body)
+	return err
+}
+
+func (r *IdempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM public.idempotency_keys WHERE key = $1;`, key)
+	return err
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.idempotency_keys WHERE expires_at <= now();`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type TaskMemberRepo struct {
+	db *sqlx.DB
+}
+
+func NewTaskMemberRepo(db *sqlx.DB) *TaskMemberRepo {
+	return &TaskMemberRepo{db: db}
+}
+
+func (r *TaskMemberRepo) List(ctx context.Context, taskID string) ([]models.TaskMember, error) {
+	const q = `
+		SELECT m.task_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM public.task_members m
+		JOIN public.users u ON u.id = m.user_id
+		WHERE m.task_id = $1
+		ORDER BY m.created_at, m.user_id;
+		`
+	out := []models.TaskMember{}
+	if err := r.db.SelectContext(ctx, &out, q, taskID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *TaskMemberRepo) Set(ctx context.Context, taskID, email string, role models.Role) (*models.TaskMember, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		WITH m AS (
+			INSERT INTO public.task_members (task_id, user_id, role)
+			SELECT $1, u.id, $3
+			FROM public.users u
+			JOIN public.workspace_members w ON w.user_id = u.id AND w.workspace_id = $4
+			WHERE u.email = $2
+			ON CONFLICT (task_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING task_id, user_id, role, created_at
+		)
+		SELECT m.task_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM m JOIN public.users u ON u.id = m.user_id;
+		`
+	var out models.TaskMember
+	if err := r.db.GetContext(ctx, &out, q, taskID, email, role, workspaceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *TaskMemberRepo) Remove(ctx context.Context, taskID, userID string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.task_members WHERE task_id = $1 AND user_id = $2;`, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrMemberNotFound
+	}
+
+	return nil
+}
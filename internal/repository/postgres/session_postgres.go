@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const sessionColumns = "id, user_id, created_at, expires_at, revoked_at"
+
+type SessionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepo(db *sqlx.DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+func (r *SessionRepo) Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*models.Session, error) {
+	const q = `
+		WITH s AS (
+			INSERT INTO public.auth_sessions (user_id, expires_at)
+			VALUES ($1, $2)
+			RETURNING ` + sessionColumns + `
+		), t AS (
+			INSERT INTO public.refresh_tokens (token_hash, session_id)
+			SELECT $3, id FROM s
+		)
+		SELECT ` + sessionColumns + ` FROM s;
+		`
+	var out models.Session
+	if err := r.db.GetContext(ctx, &out, q, userID, expiresAt, tokenHash); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *SessionRepo) GetByID(ctx context.Context, id string) (*models.Session, error) {
+	const q = `SELECT ` + sessionColumns + ` FROM public.auth_sessions WHERE id = $1;`
+
+	var out models.Session
+	if err := r.db.GetContext(ctx, &out, q, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *SessionRepo) Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	// lock the token so that only one of two concurrent refreshes wins
+	const lookup = `
+		SELECT t.used_at, s.id, s.expires_at, s.revoked_at
+		FROM public.refresh_tokens t
+		JOIN public.auth_sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1
+		FOR UPDATE OF t, s;
+		`
+	var cur struct {
+		UsedAt    *time.Time `db:"used_at"`
+		ID        string     `db:"id"`
+		ExpiresAt time.Time  `db:"expires_at"`
+		RevokedAt *time.Time `db:"revoked_at"`
+	}
+	if err := tx.GetContext(ctx, &cur, lookup, oldHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+	if cur.RevokedAt != nil || !cur.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrRefreshTokenInvalid
+	}
+	if cur.UsedAt != nil {
+		// someone is replaying an old token: the whole session is suspect
+		if _, err := tx.ExecContext(ctx, `UPDATE public.auth_sessions SET revoked_at = now() WHERE id = $1;`, cur.ID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrRefreshTokenReused
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE public.refresh_tokens SET used_at = now() WHERE token_hash = $1;`, oldHash); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO public.refresh_tokens (token_hash, session_id) VALUES ($1, $2);`, newHash, cur.ID); err != nil {
+		return nil, err
+	}
+	const extend = `UPDATE public.auth_sessions SET expires_at = $2 WHERE id = $1 RETURNING ` + sessionColumns + `;`
+	var out models.Session
+	if err := tx.GetContext(ctx, &out, extend, cur.ID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &out, tx.Commit()
+}
+
+func (r *SessionRepo) Revoke(ctx context.Context, id string) error {
+	const q = `UPDATE public.auth_sessions SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1;`
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *SessionRepo) RevokeAll(ctx context.Context, userID string) error {
+	const q = `UPDATE public.auth_sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL;`
+	_, err := r.db.ExecContext(ctx, q, userID)
+	return err
+}
+
+func (r *SessionRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM public.auth_sessions WHERE expires_at <= now() OR revoked_at IS NOT NULL;`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
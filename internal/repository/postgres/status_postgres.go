@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// StatusRepo keeps every workspace's status catalog to itself; each method
+// works in the workspace of its context.
+type StatusRepo struct {
+	db *sqlx.DB
+}
+
+func NewStatusRepo(db *sqlx.DB) *StatusRepo {
+	return &StatusRepo{db: db}
+}
+
+func (r *StatusRepo) Create(ctx context.Context, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		INSERT INTO public.task_statuses (workspace_id, name, category, position, color)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at;
+		`
+	if err := r.db.QueryRowContext(ctx, q, workspaceID, s.Name, s.Category, s.Position, s.Color).Scan(&s.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *StatusRepo) GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		SELECT name, category, position, color, created_at
+		FROM public.task_statuses
+		WHERE workspace_id = $1 AND name = $2;
+		`
+
+	var out models.StatusDefinition
+	if err := r.db.GetContext(ctx, &out, q, workspaceID, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrStatusNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *StatusRepo) List(ctx context.Context) ([]models.StatusDefinition, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		SELECT name, category, position, color, created_at
+		FROM public.task_statuses
+		WHERE workspace_id = $1
+		ORDER BY position, name;
+		`
+
+	out := []models.StatusDefinition{}
+	if err := r.db.SelectContext(ctx, &out, q, workspaceID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *StatusRepo) Update(ctx context.Context, name models.TaskStatus, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE public.task_statuses
+		SET name = $1,
+		category = $2,
+		position = $3,
+		color = $4
+		WHERE workspace_id = $5 AND name = $6
+		RETURNING name, category, position, color, created_at;
+		`
+	var out models.StatusDefinition
+	if err := r.db.QueryRowxContext(ctx, q, s.Name, s.Category, s.Position, s.Color, workspaceID, name).StructScan(&out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrStatusNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *StatusRepo) Delete(ctx context.Context, name models.TaskStatus) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	const q = `DELETE FROM public.task_statuses WHERE workspace_id = $1 AND name = $2;`
+	res, err := r.db.ExecContext(ctx, q, workspaceID, name)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrStatusInUse
+		}
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrStatusNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// TagRepo keeps every workspace's tags to itself; each method works in the
+// workspace of its context.
+type TagRepo struct {
+	db *sqlx.DB
+}
+
+func NewTagRepo(db *sqlx.DB) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+func (r *TagRepo) Create(ctx context.Context, t *models.Tag) (*models.Tag, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		INSERT INTO public.tags (workspace_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at;
+		`
+	if err := r.db.QueryRowContext(ctx, q, workspaceID, t.Name).Scan(&t.ID, &t.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *TagRepo) GetByID(ctx context.Context, id string) (*models.Tag, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `SELECT id, name, created_at FROM public.tags WHERE id = $1 AND workspace_id = $2;`
+
+	var out models.Tag
+	if err := r.db.GetContext(ctx, &out, q, id, workspaceID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *TagRepo) List(ctx context.Context) ([]models.Tag, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `SELECT id, name, created_at FROM public.tags WHERE workspace_id = $1 ORDER BY name;`
+
+	out := []models.Tag{}
+	if err := r.db.SelectContext(ctx, &out, q, workspaceID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *TagRepo) Rename(ctx context.Context, id, name string) (*models.Tag, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	const q = `
+		UPDATE public.tags
+		SET name = $1
+		WHERE id = $2 AND workspace_id = $3
+		RETURNING id, name, created_at;
+		`
+	var out models.Tag
+	if err := r.db.QueryRowxContext(ctx, q, name, id, workspaceID).StructScan(&out); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTagNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *TagRepo) Delete(ctx context.Context, id string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	const q = `DELETE FROM public.tags WHERE id = $1 AND workspace_id = $2;`
+	res, err := r.db.ExecContext(ctx, q, id, workspaceID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Attach only links tags of the current workspace; the caller checks the
+// task belongs to it.
+func (r *TagRepo) Attach(ctx context.Context, taskID, tagID string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO public.task_tags (task_id, tag_id)
+		SELECT $1, id FROM public.tags WHERE id = $2 AND workspace_id = $3
+		ON CONFLICT DO NOTHING;
+		`
+	if _, err := r.db.ExecContext(ctx, q, taskID, tagID, workspaceID); err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *TagRepo) Detach(ctx context.Context, taskID, tagID string) error {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return err
+	}
+
+	const q = `
+		DELETE FROM public.task_tags
+		WHERE task_id = $1 AND tag_id IN (SELECT id FROM public.tags WHERE id = $2 AND workspace_id = $3);
+		`
+	res, err := r.db.ExecContext(ctx, q, taskID, tagID, workspaceID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrTagNotFound
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
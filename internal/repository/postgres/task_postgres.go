@@ -5,96 +5,510 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Luc1808/TaskAPI/internal/audit"
 	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
 	"github.com/Luc1808/TaskAPI/pkg/models"
 	"github.com/jmoiron/sqlx"
 )
 
+const taskColumns = "id, parent_id, title, description, status, priority, due_at, started_at, completed_at, created_at, updated_at, version, archived_at, created_by, deleted_at"
+
+// queryer is satisfied by both *sqlx.DB and *sqlx.Tx.
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type TaskRepo struct {
-	db *sqlx.DB
+	db queryer
+	// savepoints counts the transactions nested around this repo
+	savepoints int
 }
 
 func NewTaskRepo(db *sqlx.DB) *TaskRepo {
 	return &TaskRepo{db: db}
 }
 
+func (r *TaskRepo) WithTx(ctx context.Context, fn func(tx repository.TaskRepository) error) error {
+	tx, ok := r.db.(*sqlx.Tx)
+	if ok {
+		return r.withSavepoint(ctx, tx, fn)
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err := fn(&TaskRepo{db: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TaskRepo) begin(ctx context.Context) (*sqlx.Tx, error) {
+	return stamped(ctx, r.db.(*sqlx.DB))
+}
+
+// stamped opens a transaction stamped with the request's workspace, actor
+// and id. Row-level security keeps it to the workspace's tasks, comments
+// and history, and the task_events trigger records the actor and id next
+// to every change.
+func stamped(ctx context.Context, db *sqlx.DB) (*sqlx.Tx, error) {
+	workspaceID, err := tenant.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	const q = `SELECT set_config('taskapi.workspace_id', $1, true), set_config('taskapi.actor', $2, true), set_config('taskapi.request_id', $3, true);`
+	if _, err := tx.ExecContext(ctx, q, workspaceID, audit.Actor(ctx), audit.RequestID(ctx)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// inWorkspace runs fn in a stamped transaction, for repositories over
+// tables behind row-level security.
+func inWorkspace(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := stamped(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// scoped runs fn in a stamped transaction, reusing the current one when
+// there is one. Every method goes through it, reads included, so none can
+// reach another workspace's tasks.
+func (r *TaskRepo) scoped(ctx context.Context, fn func(r *TaskRepo) error) error {
+	if _, ok := r.db.(*sqlx.Tx); ok {
+		return fn(r)
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	if err := fn(&TaskRepo{db: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TaskRepo) withSavepoint(ctx context.Context, tx *sqlx.Tx, fn func(tx repository.TaskRepository) error) error {
+	name := fmt.Sprintf("task_repo_%d", r.savepoints+1)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if err := fn(&TaskRepo{db: tx, savepoints: r.savepoints + 1}); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
 func (r *TaskRepo) Create(ctx context.Context, t *models.Task) (*models.Task, error) {
 	if err := t.Validate(); err != nil {
 		return nil, err
 	}
 
 	const q = `
-		INSERT INTO public.tasks (title, description, status, due_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at;
+		INSERT INTO public.tasks (workspace_id, parent_id, title, description, status, priority, due_at, started_at, completed_at, created_by)
+		VALUES (public.current_workspace(), $1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at, version;
 		`
-	if err := r.db.QueryRowContext(ctx, q,
-		t.Title, t.Description, t.Status,
-		t.DueAt).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		return r.db.QueryRowContext(ctx, q,
+			t.ParentID, t.Title, t.Description, t.Status, t.Priority,
+			t.DueAt, t.StartedAt, t.CompletedAt, t.CreatedBy).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt, &t.Version)
+	})
+	if err != nil {
 		return nil, err
 	}
+	t.Tags = []string{}
 
 	return t, nil
 }
 
 func (r *TaskRepo) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	const q = `
-		SELECT id, title, description, status, due_at, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM public.tasks
-		WHERE id = $1;
+		WHERE id = $1 AND deleted_at IS NULL AND workspace_id = public.current_workspace();
 		`
-	var out models.Task
-	if err := r.db.GetContext(ctx, &out, q, id); err != nil {
-		// In case there's no rows, it could return "no rows"
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, models.ErrNotFound
+	tasks := make([]models.Task, 1)
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		if err := r.db.GetContext(ctx, &tasks[0], q, id); err != nil {
+			// In case there's no rows, it could return "no rows"
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrNotFound
+			}
+			return err
 		}
+		return r.hydrate(ctx, tasks)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &out, nil
+	return &tasks[0], nil
+}
+
+func (r *TaskRepo) Role(ctx context.Context, id, userID string) (models.Role, error) {
+	const q = `
+		SELECT coalesce(public.task_role(id, $2), '')
+		FROM public.tasks
+		WHERE id = $1 AND workspace_id = public.current_workspace();
+		`
+	var role models.Role
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		err := r.db.GetContext(ctx, &role, q, id, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	})
+	return role, err
 }
 
 func (r *TaskRepo) List(ctx context.Context, f repository.ListFilter, p repository.Pagination) ([]models.Task, error) {
-	base := `
-	SELECT id, title, description, status, due_at, created_at, updated_at
-	FROM public.tasks
-	`
+	cols := taskColumns
 
-	where := []string{"1=1"}
-	args := []any{}
-	arg := 1
+	where, args := filterClause(f)
+	if f.Search != "" {
+		args = append(args, repository.PrefixTSQuery(f.Search))
+		tsq := fmt.Sprintf("to_tsquery('%s', $%d)", repository.SearchConfig, len(args))
+		cols += fmt.Sprintf(`,
+		ts_rank_cd(search_vector, %s) AS rank,
+		ts_headline('%s', %s, %s, '%s') AS snippet`,
+			tsq, repository.SearchConfig, repository.HeadlineText, tsq, repository.HeadlineOptions)
+	}
+	arg := len(args) + 1
 
-	if f.Status != nil {
-		where = append(where, fmt.Sprintf("status = $%d", arg))
-		args = append(args, *f.Status)
-		arg++
+	base := fmt.Sprintf(`
+	SELECT %s
+	FROM public.tasks
+	`, cols)
+
+	if p.After != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) > ($%d, $%d)", arg, arg+1))
+		args = append(args, p.After.CreatedAt, p.After.ID)
+		arg += 2
 	}
-	if f.Search != "" {
-		where = append(where, fmt.Sprintf("(title ILIKE $%d OR description ILIKE $%d)", arg, arg))
-		args = append(args, "%"+f.Search+"%")
-		arg++
+	if p.Before != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) < ($%d, $%d)", arg, arg+1))
+		args = append(args, p.Before.CreatedAt, p.Before.ID)
+		arg += 2
 	}
 
-	order := "ORDER BY created_at"
-	limit := 20
+	order := "ORDER BY " + strings.Join(repository.OrderTerms(f.Sort), ", ")
+	limit := repository.DefaultPageSize
 	if p.Limit > 0 {
 		limit = p.Limit
 	}
+	offset := p.Offset
+	if p.After != nil || p.Before != nil {
+		offset = 0
+	}
+	// Walk backwards from the cursor, then flip the page back below
+	if p.Before != nil {
+		order = "ORDER BY created_at DESC, id DESC"
+	}
 
 	query := fmt.Sprintf("%s WHERE %s %s LIMIT %d OFFSET %d;",
-		base, strings.Join(where, " AND "), order, limit, p.Offset)
+		base, strings.Join(where, " AND "), order, limit, offset)
 
 	out := []models.Task{}
-	if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		if err := r.db.SelectContext(ctx, &out, query, args...); err != nil {
+			return err
+		}
+		if p.Before != nil {
+			slices.Reverse(out)
+		}
+		return r.hydrate(ctx, out)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return out, nil
 }
 
+// hydrate fills in the fields that live outside public.tasks.
+func (r *TaskRepo) hydrate(ctx context.Context, tasks []models.Task) error {
+	if err := r.loadTags(ctx, tasks); err != nil {
+		return err
+	}
+	if err := r.loadProgress(ctx, tasks); err != nil {
+		return err
+	}
+	if err := r.loadCommentCounts(ctx, tasks); err != nil {
+		return err
+	}
+	return r.loadBlocked(ctx, tasks)
+}
+
+// loadCommentCounts counts the comments on every task with one round trip.
+func (r *TaskRepo) loadCommentCounts(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	const q = `
+		SELECT task_id, count(*) AS n
+		FROM public.task_comments
+		WHERE task_id = ANY($1::uuid[])
+		GROUP BY task_id;
+		`
+	var rows []struct {
+		TaskID string `db:"task_id"`
+		N      int    `db:"n"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, q, ids); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		tasks[byID[row.TaskID]].CommentCount = row.N
+	}
+
+	return nil
+}
+
+// loadBlocked flags tasks that still have at least one blocker that isn't closed.
+func (r *TaskRepo) loadBlocked(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	const q = `
+		SELECT DISTINCT d.task_id
+		FROM public.task_dependencies d
+		JOIN public.tasks b ON b.id = d.blocker_id
+		WHERE d.task_id = ANY($1::uuid[]) AND b.deleted_at IS NULL AND b.status NOT IN (` + repository.ClosedStatuses + `);
+		`
+	var blocked []string
+	if err := r.db.SelectContext(ctx, &blocked, q, ids); err != nil {
+		return err
+	}
+	for _, id := range blocked {
+		tasks[byID[id]].Blocked = true
+	}
+
+	return nil
+}
+
+// loadProgress counts direct children (and how many are closed) per task.
+func (r *TaskRepo) loadProgress(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+	}
+
+	const q = `
+		SELECT parent_id, count(*) AS total, count(*) FILTER (WHERE status IN (` + repository.ClosedStatuses + `)) AS done
+		FROM public.tasks
+		WHERE parent_id = ANY($1::uuid[]) AND deleted_at IS NULL
+		GROUP BY parent_id;
+		`
+	var rows []struct {
+		ParentID string `db:"parent_id"`
+		Total    int    `db:"total"`
+		Done     int    `db:"done"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, q, ids); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		tasks[byID[row.ParentID]].Progress = &models.Progress{Done: row.Done, Total: row.Total}
+	}
+
+	return nil
+}
+
+// loadTags fills in the tag names of every task with one round trip.
+func (r *TaskRepo) loadTags(ctx context.Context, tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	byID := make(map[string]int, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		byID[tasks[i].ID] = i
+		tasks[i].Tags = []string{}
+	}
+
+	const q = `
+		SELECT tt.task_id, g.name
+		FROM public.task_tags tt
+		JOIN public.tags g ON g.id = tt.tag_id
+		WHERE tt.task_id = ANY($1::uuid[])
+		ORDER BY g.name;
+		`
+	var rows []struct {
+		TaskID string `db:"task_id"`
+		Name   string `db:"name"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, q, ids); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		i := byID[row.TaskID]
+		tasks[i].Tags = append(tasks[i].Tags, row.Name)
+	}
+
+	return nil
+}
+
+func (r *TaskRepo) Count(ctx context.Context, f repository.ListFilter) (int, error) {
+	where, args := filterClause(f)
+	query := fmt.Sprintf("SELECT count(*) FROM public.tasks WHERE %s;", strings.Join(where, " AND "))
+
+	var n int
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		return r.db.GetContext(ctx, &n, query, args...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// filterClause turns a ListFilter into AND-ed conditions with $n placeholders.
+// Every condition appends exactly one arg per new placeholder number, so the
+// next free placeholder is always len(args)+1.
+func filterClause(f repository.ListFilter) ([]string, []any) {
+	where := []string{"deleted_at IS NULL", "workspace_id = public.current_workspace()"}
+	if f.Trashed {
+		where[0] = "deleted_at IS NOT NULL"
+	}
+	switch f.Archived {
+	case repository.ArchivedExclude:
+		where = append(where, "archived_at IS NULL")
+	case repository.ArchivedOnly:
+		where = append(where, "archived_at IS NOT NULL")
+	}
+	args := []any{}
+	arg := 1
+
+	if f.ParentID != nil {
+		where = append(where, fmt.Sprintf("parent_id = $%d", arg))
+		args = append(args, *f.ParentID)
+		arg++
+	}
+	if f.BlockersOf != nil {
+		where = append(where, fmt.Sprintf("id IN (SELECT blocker_id FROM public.task_dependencies WHERE task_id = $%d)", arg))
+		args = append(args, *f.BlockersOf)
+		arg++
+	}
+	if f.Status != nil {
+		where = append(where, fmt.Sprintf("status = $%d", arg))
+		args = append(args, *f.Status)
+		arg++
+	}
+	if f.Priority != nil {
+		where = append(where, fmt.Sprintf("priority = $%d", arg))
+		args = append(args, *f.Priority)
+		arg++
+	}
+	if f.DueBefore != nil {
+		where = append(where, fmt.Sprintf("due_at < $%d", arg))
+		args = append(args, *f.DueBefore)
+		arg++
+	}
+	if f.DueAfter != nil {
+		where = append(where, fmt.Sprintf("due_at > $%d", arg))
+		args = append(args, *f.DueAfter)
+		arg++
+	}
+	if f.Overdue {
+		where = append(where, "due_at < now() AND status NOT IN ("+repository.ClosedStatuses+")")
+	}
+	if len(f.Tags) > 0 {
+		where = append(where, fmt.Sprintf(`id IN (
+			SELECT tt.task_id FROM public.task_tags tt JOIN public.tags g ON g.id = tt.tag_id
+			WHERE g.name = ANY($%d) GROUP BY tt.task_id HAVING count(*) = cardinality($%d))`, arg, arg))
+		args = append(args, f.Tags)
+		arg++
+	}
+	if len(f.TagsAny) > 0 {
+		where = append(where, fmt.Sprintf(`id IN (
+			SELECT tt.task_id FROM public.task_tags tt JOIN public.tags g ON g.id = tt.tag_id
+			WHERE g.name = ANY($%d))`, arg))
+		args = append(args, f.TagsAny)
+		arg++
+	}
+	if len(f.TagsNone) > 0 {
+		where = append(where, fmt.Sprintf(`id NOT IN (
+			SELECT tt.task_id FROM public.task_tags tt JOIN public.tags g ON g.id = tt.tag_id
+			WHERE g.name = ANY($%d))`, arg))
+		args = append(args, f.TagsNone)
+		arg++
+	}
+	if f.Search != "" {
+		where = append(where, fmt.Sprintf("search_vector @@ to_tsquery('%s', $%d)", repository.SearchConfig, arg))
+		args = append(args, repository.PrefixTSQuery(f.Search))
+		arg++
+	}
+	if f.VisibleTo != "" {
+		minRole := f.MinRole
+		if minRole == "" {
+			minRole = models.RoleViewer
+		}
+		members := fmt.Sprintf("user_id = $%d AND role = ANY($%d)", arg, arg+1)
+		where = append(where, "id IN ("+repository.VisibleTaskIDs(members)+")")
+		args = append(args, f.VisibleTo, models.RolesIncluding(minRole))
+		arg += 2
+	}
+
+	return where, args
+}
+
 func (r *TaskRepo) Update(ctx context.Context, t *models.Task) (*models.Task, error) {
 	if err := t.Validate(); err != nil {
 		return nil, err
@@ -105,27 +519,212 @@ func (r *TaskRepo) Update(ctx context.Context, t *models.Task) (*models.Task, er
 		SET title = $1,
 		description = $2,
 		status = $3,
-		due_at = $4,
-		updated_at = now()
-		WHERE id = $5
-		RETURNING created_at, updated_at;
+		priority = $4,
+		due_at = $5,
+		parent_id = $6,
+		started_at = $7,
+		completed_at = $8,
+		archived_at = $11,
+		updated_at = now(),
+		version = version + 1
+		WHERE id = $9 AND version = $10 AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+		RETURNING created_at, updated_at, version;
 		`
-	var createdAt, updatedAt = t.CreatedAt, t.UpdatedAt
-	if err := r.db.QueryRowxContext(ctx, q, t.Title, t.Description, t.Status, t.DueAt, t.ID).Scan(&createdAt, &updatedAt); err != nil {
+	var createdAt, updatedAt, version = t.CreatedAt, t.UpdatedAt, t.Version
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		err := r.db.QueryRowxContext(ctx, q, t.Title, t.Description, t.Status, t.Priority, t.DueAt, t.ParentID,
+			t.StartedAt, t.CompletedAt, t.ID, t.Version, t.ArchivedAt).Scan(&createdAt, &updatedAt, &version)
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, models.ErrNotFound
+			return r.missOrConflict(ctx, t.ID)
 		}
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 	t.CreatedAt = createdAt
 	t.UpdatedAt = updatedAt
+	t.Version = version
 
 	return t, nil
 }
 
-func (r *TaskRepo) Delete(ctx context.Context, id string) error {
-	const q = `DELETE FROM public.tasks WHERE id = $1;`
-	res, err := r.db.ExecContext(ctx, q, id)
+// missOrConflict tells a missing task apart from a stale version after a
+// conditional update matched no rows.
+func (r *TaskRepo) missOrConflict(ctx context.Context, id string) error {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM public.tasks WHERE id = $1 AND deleted_at IS NULL AND workspace_id = public.current_workspace());`, id); err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrVersionConflict
+	}
+	return models.ErrNotFound
+}
+
+func (r *TaskRepo) Delete(ctx context.Context, id string, version int) error {
+	const q = `
+		WITH trashed AS (
+			UPDATE public.tasks SET deleted_at = now()
+			WHERE id = $1 AND ($2 = 0 OR version = $2) AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			RETURNING id
+		), orphaned AS (
+			UPDATE public.tasks SET parent_id = NULL, updated_at = now(), version = version + 1
+			WHERE parent_id IN (SELECT id FROM trashed) AND deleted_at IS NULL
+		)
+		SELECT count(*) FROM trashed;
+		`
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		var n int
+		if err := r.db.GetContext(ctx, &n, q, id, version); err != nil {
+			return err
+		}
+		if n == 0 {
+			return r.missOrConflict(ctx, id)
+		}
+		return nil
+	})
+}
+
+func (r *TaskRepo) Subtree(ctx context.Context, id string) ([]models.Task, error) {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 1 AS depth FROM public.tasks WHERE parent_id = $1 AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NULL
+		)
+		SELECT %s
+		FROM public.tasks
+		JOIN (SELECT id, min(depth) AS depth FROM sub GROUP BY id) d USING (id)
+		ORDER BY d.depth, created_at, id;
+		`, repository.MaxTreeDepth, taskColumns)
+
+	out := []models.Task{}
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		if err := r.db.SelectContext(ctx, &out, q, id); err != nil {
+			return err
+		}
+		return r.hydrate(ctx, out)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *TaskRepo) DeleteTree(ctx context.Context, id string, version int) error {
+	// now() is fixed per transaction, so the whole tree shares one deleted_at
+	// and Restore can tell it apart from tasks trashed separately
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 0 AS depth FROM public.tasks
+			WHERE id = $1 AND ($2 = 0 OR version = $2) AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NULL
+		)
+		UPDATE public.tasks SET deleted_at = now() WHERE id IN (SELECT id FROM sub);
+		`, repository.MaxTreeDepth)
+
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		err := r.execOne(ctx, q, id, version)
+		if errors.Is(err, models.ErrNotFound) {
+			return r.missOrConflict(ctx, id)
+		}
+		return err
+	})
+}
+
+func (r *TaskRepo) Restore(ctx context.Context, id string) (*models.Task, error) {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE root AS (
+			SELECT id, parent_id, deleted_at FROM public.tasks WHERE id = $1 AND deleted_at IS NOT NULL AND workspace_id = public.current_workspace()
+		), sub AS (
+			SELECT id, 0 AS depth FROM root
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at = (SELECT deleted_at FROM root)
+		)
+		UPDATE public.tasks
+		SET deleted_at = NULL,
+		updated_at = now(),
+		version = version + 1,
+		parent_id = CASE
+			WHEN id = $1 AND parent_id IN (SELECT id FROM public.tasks WHERE deleted_at IS NOT NULL) THEN NULL
+			ELSE parent_id
+		END
+		WHERE id IN (SELECT id FROM sub);
+		`, repository.MaxTreeDepth)
+
+	var out *models.Task
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		if err := r.execOne(ctx, q, id); err != nil {
+			return err
+		}
+		var err error
+		out, err = r.GetByID(ctx, id)
+		return err
+	})
+	return out, err
+}
+
+func (r *TaskRepo) Purge(ctx context.Context, id string) error {
+	q := fmt.Sprintf(`
+		WITH RECURSIVE sub AS (
+			SELECT id, 0 AS depth FROM public.tasks WHERE id = $1 AND deleted_at IS NOT NULL AND workspace_id = public.current_workspace()
+			UNION ALL
+			SELECT t.id, sub.depth + 1
+			FROM public.tasks t
+			JOIN sub ON t.parent_id = sub.id
+			WHERE sub.depth < %d AND t.deleted_at IS NOT NULL
+		)
+		DELETE FROM public.tasks WHERE id IN (SELECT id FROM sub);
+		`, repository.MaxTreeDepth)
+
+	return r.scoped(ctx, func(r *TaskRepo) error {
+		return r.execOne(ctx, q, id)
+	})
+}
+
+func (r *TaskRepo) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	return r.execCount(ctx, `DELETE FROM public.tasks WHERE deleted_at < $1 AND workspace_id = public.current_workspace();`, before)
+}
+
+func (r *TaskRepo) ArchiveClosed(ctx context.Context, before time.Time) (int64, error) {
+	const q = `
+		UPDATE public.tasks
+		SET archived_at = now(), updated_at = now(), version = version + 1
+		WHERE archived_at IS NULL AND deleted_at IS NULL AND workspace_id = public.current_workspace()
+		AND completed_at < $1 AND status IN (` + repository.ClosedStatuses + `);
+		`
+	return r.execCount(ctx, q, before)
+}
+
+// execCount runs a bulk write and reports how many rows it touched.
+func (r *TaskRepo) execCount(ctx context.Context, q string, args ...any) (int64, error) {
+	var n int64
+	err := r.scoped(ctx, func(r *TaskRepo) error {
+		res, err := r.db.ExecContext(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return err
+	})
+	return n, err
+}
+
+// execOne runs q and reports models.ErrNotFound if it touched no rows.
+func (r *TaskRepo) execOne(ctx context.Context, q string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, q, args...)
 	if err != nil {
 		return err
 	}
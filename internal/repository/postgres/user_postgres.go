@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const userColumns = "id, email, name, password_hash, created_at, updated_at"
+
+type UserRepo struct {
+	db *sqlx.DB
+}
+
+func NewUserRepo(db *sqlx.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) Create(ctx context.Context, u *models.User) (*models.User, error) {
+	const q = `
+		INSERT INTO public.users (email, name, password_hash)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at;
+		`
+	if err := r.db.QueryRowContext(ctx, q, u.Email, u.Name, u.PasswordHash).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getBy(ctx, "id", id)
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getBy(ctx, "email", email)
+}
+
+func (r *UserRepo) getBy(ctx context.Context, column, value string) (*models.User, error) {
+	q := `SELECT ` + userColumns + ` FROM public.users WHERE ` + column + ` = $1;`
+
+	var out models.User
+	if err := r.db.GetContext(ctx, &out, q, value); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *UserRepo) UpdatePassword(ctx context.Context, id, hash string) error {
+	const q = `
+		WITH updated AS (
+			UPDATE public.users SET password_hash = $2 WHERE id = $1 RETURNING id
+		), tokens AS (
+			DELETE FROM public.password_reset_tokens WHERE user_id IN (SELECT id FROM updated)
+		), sessions AS (
+			UPDATE public.auth_sessions SET revoked_at = now()
+			WHERE user_id IN (SELECT id FROM updated) AND revoked_at IS NULL
+		)
+		SELECT count(*) FROM updated;
+		`
+	var n int
+	if err := r.db.GetContext(ctx, &n, q, id, hash); err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepo) CreateResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	const q = `INSERT INTO public.password_reset_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3);`
+	_, err := r.db.ExecContext(ctx, q, tokenHash, userID, expiresAt)
+	return err
+}
+
+func (r *UserRepo) ConsumeResetToken(ctx context.Context, tokenHash string) (string, error) {
+	const q = `
+		DELETE FROM public.password_reset_tokens
+		WHERE token_hash = $1 AND expires_at > now()
+		RETURNING user_id;
+		`
+	var userID string
+	if err := r.db.GetContext(ctx, &userID, q, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", models.ErrResetTokenInvalid
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
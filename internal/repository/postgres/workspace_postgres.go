@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type WorkspaceRepo struct {
+	db *sqlx.DB
+}
+
+func NewWorkspaceRepo(db *sqlx.DB) *WorkspaceRepo {
+	return &WorkspaceRepo{db: db}
+}
+
+func (r *WorkspaceRepo) Create(ctx context.Context, w *models.Workspace, userID string) (*models.Workspace, error) {
+	const q = `
+		WITH w AS (
+			INSERT INTO public.workspaces (slug, name)
+			VALUES ($1, $2)
+			RETURNING id, slug, name, created_at
+		), m AS (
+			INSERT INTO public.workspace_members (workspace_id, user_id, role)
+			SELECT id, $3, 'admin' FROM w
+		)
+		SELECT id, slug, name, created_at, 'admin' AS role FROM w;
+		`
+	var out models.Workspace
+	if err := r.db.GetContext(ctx, &out, q, w.Slug, w.Name, userID); err != nil {
+		if isUniqueViolation(err) {
+			return nil, models.ErrConflict
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) ForUser(ctx context.Context, userID string) ([]models.Workspace, error) {
+	const q = `
+		SELECT w.id, w.slug, w.name, w.created_at, m.role
+		FROM public.workspace_members m
+		JOIN public.workspaces w ON w.id = m.workspace_id
+		WHERE m.user_id = $1
+		ORDER BY m.created_at, w.id;
+		`
+	out := []models.Workspace{}
+	if err := r.db.SelectContext(ctx, &out, q, userID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) Membership(ctx context.Context, slug, userID string) (*models.Workspace, error) {
+	const q = `
+		SELECT w.id, w.slug, w.name, w.created_at, m.role
+		FROM public.workspaces w
+		JOIN public.workspace_members m ON m.workspace_id = w.id AND m.user_id = $2
+		WHERE w.slug = $1;
+		`
+	var out models.Workspace
+	if err := r.db.GetContext(ctx, &out, q, slug, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) IDs(ctx context.Context) ([]string, error) {
+	out := []string{}
+	if err := r.db.SelectContext(ctx, &out, `SELECT id FROM public.workspaces ORDER BY created_at, id;`); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) Members(ctx context.Context, workspaceID string) ([]models.WorkspaceUser, error) {
+	const q = `
+		SELECT m.workspace_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM public.workspace_members m
+		JOIN public.users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at, m.user_id;
+		`
+	out := []models.WorkspaceUser{}
+	if err := r.db.SelectContext(ctx, &out, q, workspaceID); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (r *WorkspaceRepo) SetMember(ctx context.Context, workspaceID, email string, role models.WorkspaceRole) (*models.WorkspaceUser, error) {
+	const q = `
+		WITH m AS (
+			INSERT INTO public.workspace_members (workspace_id, user_id, role)
+			SELECT $1, id, $3 FROM public.users WHERE email = $2
+			ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role
+			RETURNING workspace_id, user_id, role, created_at
+		)
+		SELECT m.workspace_id, m.user_id, u.email, u.name, m.role, m.created_at
+		FROM m JOIN public.users u ON u.id = m.user_id;
+		`
+	var out models.WorkspaceUser
+	if err := r.db.GetContext(ctx, &out, q, workspaceID, email, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM public.workspace_members WHERE workspace_id = $1 AND user_id = $2;`, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ErrWorkspaceUserNotFound
+	}
+
+	// tasks are behind row-level security, so name the workspace first
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('taskapi.workspace_id', $1, true);`, workspaceID); err != nil {
+		return err
+	}
+	const q = `
+		DELETE FROM public.task_members
+		WHERE user_id = $2 AND task_id IN (SELECT id FROM public.tasks WHERE workspace_id = $1);
+		`
+	if _, err := tx.ExecContext(ctx, q, workspaceID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public.api_keys WHERE workspace_id = $1 AND user_id = $2;`, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
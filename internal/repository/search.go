@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchConfig is the text search configuration used by the search_vector column.
+const SearchConfig = "english"
+
+// HeadlineOptions controls ts_headline snippets in search results.
+const HeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2"
+
+// HeadlineText is the SQL for the text snippets are cut from: title and
+// description with HTML special characters escaped, so the <mark> tags are
+// the only markup in a snippet and it is safe to render as HTML.
+const HeadlineText = `replace(replace(replace(replace(replace(` +
+	`title || ' ' || coalesce(description, ''), ` +
+	`'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+
+// PrefixTSQuery turns free text into a to_tsquery expression that ANDs every
+// word as a prefix match ("fix log" -> "fix:* & log:*"). Anything that isn't
+// a letter or digit is dropped, so user input can't inject tsquery operators.
+// It returns "" when nothing searchable is left.
+func PrefixTSQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = strings.ToLower(w) + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// SessionRepository stores login sessions and their refresh tokens. Tokens
+// are only ever handled as hashes.
+type SessionRepository interface {
+	// Create starts a session with its first refresh token
+	Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*models.Session, error)
+	GetByID(ctx context.Context, id string) (*models.Session, error)
+	// Rotate marks a refresh token used, stores its successor and extends
+	// the session to expiresAt. Unknown tokens, and tokens of revoked or
+	// expired sessions, give models.ErrRefreshTokenInvalid. A token that
+	// was already used revokes its session and gives
+	// models.ErrRefreshTokenReused.
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAll(ctx context.Context, userID string) error
+	// DeleteExpired drops expired and revoked sessions
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,57 @@
+package repository
+
+// SortKey is a task field the list can be ordered by. Keys double as
+// column names, so only the constants below may ever reach SQL.
+type SortKey string
+
+const (
+	SortCreatedAt SortKey = "created_at"
+	SortUpdatedAt SortKey = "updated_at"
+	SortDueAt     SortKey = "due_at"
+	SortPriority  SortKey = "priority"
+	SortTitle     SortKey = "title"
+	SortStatus    SortKey = "status"
+	// SortRank is the full-text relevance and only exists on searches
+	SortRank SortKey = "rank"
+)
+
+var sortable = map[SortKey]bool{
+	SortCreatedAt: true,
+	SortUpdatedAt: true,
+	SortDueAt:     true,
+	SortPriority:  true,
+	SortTitle:     true,
+	SortStatus:    true,
+	SortRank:      true,
+}
+
+func (k SortKey) Valid() bool {
+	return sortable[k]
+}
+
+type SortField struct {
+	Key  SortKey
+	Desc bool
+}
+
+// OrderTerms renders sorts as ORDER BY terms shared by every backend.
+// Nulls always sort last, and id is appended as a stable tie-breaker;
+// with no sorts the default is created_at, id ascending.
+func OrderTerms(sorts []SortField) []string {
+	if len(sorts) == 0 {
+		sorts = []SortField{{Key: SortCreatedAt}}
+	}
+
+	terms := make([]string, 0, len(sorts)+1)
+	for _, s := range sorts {
+		if !s.Key.Valid() {
+			continue
+		}
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		terms = append(terms, string(s.Key)+" "+dir+" NULLS LAST")
+	}
+	return append(terms, "id ASC")
+}
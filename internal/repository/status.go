@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// StatusRepository manages the status catalog of the workspace in the
+// context; every workspace has its own.
+type StatusRepository interface {
+	Create(ctx context.Context, s *models.StatusDefinition) (*models.StatusDefinition, error)
+	GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error)
+	// List is ordered by position, then name
+	List(ctx context.Context) ([]models.StatusDefinition, error)
+	// Update may rename the status; tasks follow the new name
+	Update(ctx context.Context, name models.TaskStatus, s *models.StatusDefinition) (*models.StatusDefinition, error)
+	Delete(ctx context.Context, name models.TaskStatus) error
+}
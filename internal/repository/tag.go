@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, t *models.Tag) (*models.Tag, error)
+	GetByID(ctx context.Context, id string) (*models.Tag, error)
+	List(ctx context.Context) ([]models.Tag, error)
+	Rename(ctx context.Context, id, name string) (*models.Tag, error)
+	Delete(ctx context.Context, id string) error
+
+	// Attach is idempotent; Detach reports ErrTagNotFound if the link didn't exist
+	Attach(ctx context.Context, taskID, tagID string) error
+	Detach(ctx context.Context, taskID, tagID string) error
+}
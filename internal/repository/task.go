@@ -2,24 +2,131 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/Luc1808/TaskAPI/pkg/models"
 )
 
+// ClosedStatuses selects every status in the current workspace's closed
+// category, for use as "status IN (...)" in both SQL backends.
+const ClosedStatuses = "SELECT name FROM public.task_statuses WHERE category = 'closed' AND workspace_id = public.current_workspace()"
+
+// MaxTreeDepth bounds how deep subtask hierarchies may nest.
+const MaxTreeDepth = 50
+
+// VisibleTaskIDs selects, for use as "id IN (...)" in both SQL backends, the
+// subtrees under the task_members rows matching members. It is the set of
+// tasks whose task_role would pass, found with one walk down the tree
+// instead of a walk up per task.
+func VisibleTaskIDs(members string) string {
+	return `WITH RECURSIVE visible (id, depth) AS (
+			SELECT task_id, 1 FROM public.task_members WHERE ` + members + `
+			UNION
+			SELECT t.id, v.depth + 1 FROM public.tasks t JOIN visible v ON t.parent_id = v.id
+			WHERE v.depth < ` + strconv.Itoa(MaxTreeDepth) + `
+		)
+		SELECT id FROM visible`
+}
+
 type ListFilter struct {
-	Status *models.TaskStatus
-	Search string
+	ParentID *string
+	// BlockersOf keeps only the tasks blocking this task
+	BlockersOf *string
+	Status     *models.TaskStatus
+	Priority   *int
+	Search     string
+
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// Overdue keeps tasks past their due date that are not closed yet
+	Overdue bool
+
+	// Tag names: must carry all of Tags, at least one of TagsAny, none of TagsNone
+	Tags     []string
+	TagsAny  []string
+	TagsNone []string
+
+	// Trashed lists the trash instead of live tasks
+	Trashed bool
+	// Archived controls whether archived tasks are listed
+	Archived ArchivedFilter
+
+	// VisibleTo keeps the tasks this user holds at least MinRole on (any
+	// role when empty), directly or through an ancestor
+	VisibleTo string
+	MinRole   models.Role
+
+	// Sort is applied in order; see OrderTerms
+	Sort []SortField
+}
+
+// ArchivedFilter selects tasks by their archived state.
+type ArchivedFilter int
+
+const (
+	// ArchivedExclude hides archived tasks, the default
+	ArchivedExclude ArchivedFilter = iota
+	ArchivedInclude
+	ArchivedOnly
+)
+
+// Cursor is a keyset position on (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
 }
 
+// DefaultPageSize is the page size when none was asked for, and the limit
+// repositories fall back to. MaxPageSize is the largest page served.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Pagination uses LIMIT/OFFSET unless After or Before is set, in which
+// case rows are read by keyset on (created_at, id) and Offset is ignored.
+// Results are always returned in ascending (created_at, id) order.
 type Pagination struct {
 	Limit  int
 	Offset int
+	After  *Cursor
+	Before *Cursor
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, t *models.Task) (*models.Task, error)
 	GetByID(ctx context.Context, id string) (*models.Task, error)
 	List(ctx context.Context, f ListFilter, p Pagination) ([]models.Task, error)
+	Count(ctx context.Context, f ListFilter) (int, error)
 	Update(ctx context.Context, t *models.Task) (*models.Task, error)
-	Delete(ctx context.Context, id string) error
+
+	// Role is the strongest role userID holds on task id or any of its
+	// ancestors, trashed or not; empty when they hold none
+	Role(ctx context.Context, id, userID string) (models.Role, error)
+
+	// Subtree returns every descendant of id (not id itself), parents before children
+	Subtree(ctx context.Context, id string) ([]models.Task, error)
+	// Delete moves a task to the trash; its live children move to the top level.
+	// A non-zero version makes it conditional, failing with ErrVersionConflict
+	// if the task has moved past that version.
+	Delete(ctx context.Context, id string, version int) error
+	// DeleteTree trashes id together with all of its descendants, conditional
+	// on version like Delete
+	DeleteTree(ctx context.Context, id string, version int) error
+	// Restore takes a trashed task out of the trash along with the descendants
+	// trashed with it. It returns to the top level if its parent is still trashed.
+	Restore(ctx context.Context, id string) (*models.Task, error)
+	// Purge permanently deletes a trashed task and its trashed descendants
+	Purge(ctx context.Context, id string) error
+	// PurgeTrash permanently deletes everything trashed before the given time
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	// ArchiveClosed archives live tasks in a closed status completed before the given time
+	ArchiveClosed(ctx context.Context, before time.Time) (int64, error)
+
+	// WithTx runs fn against a repository bound to one transaction, committing
+	// if fn returns nil and rolling back otherwise. Calling WithTx on the
+	// repository fn receives nests via a savepoint.
+	WithTx(ctx context.Context, fn func(tx TaskRepository) error) error
 }
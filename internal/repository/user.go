@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type UserRepository interface {
+	// Create reports models.ErrConflict when the email is taken
+	Create(ctx context.Context, u *models.User) (*models.User, error)
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	// GetByEmail expects the email already lower-cased
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// UpdatePassword also drops the user's outstanding reset tokens and
+	// revokes their sessions
+	UpdatePassword(ctx context.Context, id, hash string) error
+
+	CreateResetToken(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	// ConsumeResetToken deletes an unexpired token and returns its user id,
+	// or models.ErrResetTokenInvalid
+	ConsumeResetToken(ctx context.Context, tokenHash string) (string, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// WorkspaceRepository manages workspaces and who belongs to them. It is the
+// one repository that works across workspaces, since it is what picks the
+// workspace in the first place.
+type WorkspaceRepository interface {
+	// Create adds the workspace with userID as its admin; models.ErrConflict
+	// if the slug is taken
+	Create(ctx context.Context, w *models.Workspace, userID string) (*models.Workspace, error)
+	// ForUser lists the workspaces userID belongs to with their role in
+	// each, the one they joined first first
+	ForUser(ctx context.Context, userID string) ([]models.Workspace, error)
+	// Membership is the workspace with this slug and userID's role in it;
+	// models.ErrWorkspaceNotFound if there's none or they don't belong to it
+	Membership(ctx context.Context, slug, userID string) (*models.Workspace, error)
+	// IDs lists every workspace, for background jobs
+	IDs(ctx context.Context) ([]string, error)
+
+	// Members is ordered by when the member joined
+	Members(ctx context.Context, workspaceID string) ([]models.WorkspaceUser, error)
+	// SetMember adds the user with this email or changes their role;
+	// models.ErrUserNotFound if there's no such user
+	SetMember(ctx context.Context, workspaceID, email string, role models.WorkspaceRole) (*models.WorkspaceUser, error)
+	// RemoveMember also drops the user's roles on the workspace's tasks and
+	// their API keys for it; models.ErrWorkspaceUserNotFound if they weren't
+	// a member
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+const (
+	maxAPIKeyName = 100
+	// apiKeyPrefixLen is how much of a key is kept in clear for display
+	apiKeyPrefixLen = len(auth.APIKeyPrefix) + 6
+)
+
+var (
+	ErrInvalidAPIKeyName = errors.New("name is required and must be <= 100 characters")
+	ErrInvalidScope      = errors.New("scopes must be one or more of " + strings.Join(auth.Scopes, ", "))
+	ErrInvalidExpiry     = errors.New("expires_at must be in the future")
+	ErrAPIKeyNotFound    = errors.New("api key not found")
+	ErrInvalidAPIKey     = errors.New("api key is invalid or expired")
+)
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is the only time the key itself is returned.
+type CreatedAPIKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyService struct {
+	keys repository.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{keys: keys, now: time.Now}
+}
+
+// CreateAPIKey makes a key for the current workspace; it won't work in any
+// other.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, in CreateAPIKeyInput) (*CreatedAPIKey, error) {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspaceID := tenant.WorkspaceID(ctx)
+	if workspaceID == "" {
+		return nil, ErrNoWorkspace
+	}
+
+	name := strings.TrimSpace(in.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAPIKeyName {
+		return nil, ErrInvalidAPIKeyName
+	}
+	if len(in.Scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	var scopes []string
+	for _, sc := range in.Scopes {
+		if !slices.Contains(auth.Scopes, sc) {
+			return nil, ErrInvalidScope
+		}
+		if !slices.Contains(scopes, sc) {
+			scopes = append(scopes, sc)
+		}
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(s.now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	secret, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	key := auth.APIKeyPrefix + secret
+
+	created, err := s.keys.Create(ctx, &models.APIKey{
+		UserID:      p.UserID,
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Prefix:      key[:apiKeyPrefixLen],
+		KeyHash:     hashToken(key),
+		Scopes:      scopes,
+		ExpiresAt:   in.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKey{APIKey: created, Key: key}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.keys.List(ctx, p.UserID)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.keys.Delete(ctx, p.UserID, id)
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// VerifyAPIKey resolves a key to a principal limited to the key's scopes,
+// and records the use.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, auth.APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	k, err := s.keys.GetByHash(ctx, hashToken(key))
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(s.now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.keys.Touch(ctx, k.ID); err != nil {
+		return nil, err
+	}
+	return &auth.Principal{UserID: k.UserID, APIKeyID: k.ID, Scopes: k.Scopes, WorkspaceID: k.WorkspaceID}, nil
+}
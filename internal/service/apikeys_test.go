@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeAPIKeyRepo struct {
+	keys    []models.APIKey
+	touched map[string]int
+}
+
+func (f *fakeAPIKeyRepo) Create(_ context.Context, k *models.APIKey) (*models.APIKey, error) {
+	copy := *k
+	copy.ID = fmt.Sprintf("k%d", len(f.keys)+1)
+	copy.CreatedAt = time.Now()
+	f.keys = append(f.keys, copy)
+	return &copy, nil
+}
+
+func (f *fakeAPIKeyRepo) List(_ context.Context, userID string) ([]models.APIKey, error) {
+	out := []models.APIKey{}
+	for i := len(f.keys) - 1; i >= 0; i-- {
+		if f.keys[i].UserID == userID {
+			out = append(out, f.keys[i])
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAPIKeyRepo) GetByHash(_ context.Context, keyHash string) (*models.APIKey, error) {
+	for _, k := range f.keys {
+		if k.KeyHash == keyHash {
+			return &k, nil
+		}
+	}
+	return nil, models.ErrAPIKeyNotFound
+}
+
+func (f *fakeAPIKeyRepo) Delete(_ context.Context, userID, id string) error {
+	for i, k := range f.keys {
+		if k.ID == id && k.UserID == userID {
+			f.keys = append(f.keys[:i], f.keys[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrAPIKeyNotFound
+}
+
+func (f *fakeAPIKeyRepo) Touch(_ context.Context, id string) error {
+	if f.touched == nil {
+		f.touched = map[string]int{}
+	}
+	f.touched[id]++
+	return nil
+}
+
+// sessionCtx signs userID in with a session, working in workspace "w1".
+func sessionCtx(userID string) context.Context {
+	ctx := tenant.WithWorkspace(context.Background(), "w1")
+	return auth.WithPrincipal(ctx, &auth.Principal{UserID: userID, SessionID: "s-" + userID})
+}
+
+func TestCreateAndVerifyAPIKey(t *testing.T) {
+	repo := &fakeAPIKeyRepo{}
+	svc := NewAPIKeyService(repo)
+
+	created, err := svc.CreateAPIKey(sessionCtx("u1"), CreateAPIKeyInput{
+		Name:   " ci ",
+		Scopes: []string{auth.ScopeTasksRead, auth.ScopeTasksRead},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, auth.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("unexpected key %q with prefix %q", created.Key, created.Prefix)
+	}
+	if created.Name != "ci" || len(created.Scopes) != 1 {
+		t.Fatalf("unexpected key %+v", created.APIKey)
+	}
+	if strings.Contains(repo.keys[0].KeyHash, created.Key) {
+		t.Fatalf("key stored in clear")
+	}
+
+	p, err := svc.VerifyAPIKey(context.Background(), created.Key)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if p.UserID != "u1" || p.APIKeyID != created.ID || p.SessionID != "" || p.WorkspaceID != "w1" {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	if !p.Allows(auth.ScopeTasksRead) || p.Allows(auth.ScopeTasksWrite) {
+		t.Fatalf("scopes not applied: %+v", p.Scopes)
+	}
+	if repo.touched[created.ID] != 1 {
+		t.Fatalf("last use not recorded")
+	}
+
+	for _, key := range []string{"", "tapi_unknown", created.Key[len(auth.APIKeyPrefix):]} {
+		if _, err := svc.VerifyAPIKey(context.Background(), key); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("%q: expected ErrInvalidAPIKey, got %v", key, err)
+		}
+	}
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	svc := NewAPIKeyService(&fakeAPIKeyRepo{})
+	ctx := sessionCtx("u1")
+
+	past := time.Now().Add(-time.Minute)
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{Name: "old", Scopes: auth.Scopes, ExpiresAt: &past}); !errors.Is(err, ErrInvalidExpiry) {
+		t.Fatalf("expected ErrInvalidExpiry, got %v", err)
+	}
+
+	soon := time.Now().Add(time.Hour)
+	created, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{Name: "soon", Scopes: auth.Scopes, ExpiresAt: &soon})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifyAPIKey(context.Background(), created.Key); err != nil {
+		t.Fatalf("verify before expiry: %v", err)
+	}
+	svc.now = func() time.Time { return soon.Add(time.Second) }
+	if _, err := svc.VerifyAPIKey(context.Background(), created.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey after expiry, got %v", err)
+	}
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	svc := NewAPIKeyService(&fakeAPIKeyRepo{})
+	ctx := sessionCtx("u1")
+	cases := []struct {
+		in   CreateAPIKeyInput
+		want error
+	}{
+		{CreateAPIKeyInput{Name: "", Scopes: auth.Scopes}, ErrInvalidAPIKeyName},
+		{CreateAPIKeyInput{Name: strings.Repeat("n", 101), Scopes: auth.Scopes}, ErrInvalidAPIKeyName},
+		{CreateAPIKeyInput{Name: "ci"}, ErrInvalidScope},
+		{CreateAPIKeyInput{Name: "ci", Scopes: []string{"admin"}}, ErrInvalidScope},
+	}
+	for _, c := range cases {
+		if _, err := svc.CreateAPIKey(ctx, c.in); !errors.Is(err, c.want) {
+			t.Errorf("%+v: expected %v, got %v", c.in, c.want, err)
+		}
+	}
+}
+
+func TestAPIKeysNeedASession(t *testing.T) {
+	svc := NewAPIKeyService(&fakeAPIKeyRepo{})
+	in := CreateAPIKeyInput{Name: "ci", Scopes: auth.Scopes}
+
+	if _, err := svc.CreateAPIKey(context.Background(), in); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+	created, err := svc.CreateAPIKey(sessionCtx("u1"), in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := svc.VerifyAPIKey(context.Background(), created.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyCtx := auth.WithPrincipal(context.Background(), p)
+	if _, err := svc.CreateAPIKey(keyCtx, in); !errors.Is(err, ErrSessionRequired) {
+		t.Fatalf("api key minted another key: %v", err)
+	}
+	if _, err := svc.ListAPIKeys(keyCtx); !errors.Is(err, ErrSessionRequired) {
+		t.Fatalf("expected ErrSessionRequired, got %v", err)
+	}
+}
+
+func TestListAndRevokeAPIKeys(t *testing.T) {
+	svc := NewAPIKeyService(&fakeAPIKeyRepo{})
+	mine, theirs := sessionCtx("u1"), sessionCtx("u2")
+
+	first, _ := svc.CreateAPIKey(mine, CreateAPIKeyInput{Name: "first", Scopes: auth.Scopes})
+	second, _ := svc.CreateAPIKey(mine, CreateAPIKeyInput{Name: "second", Scopes: auth.Scopes})
+	other, _ := svc.CreateAPIKey(theirs, CreateAPIKeyInput{Name: "other", Scopes: auth.Scopes})
+
+	keys, err := svc.ListAPIKeys(mine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != second.ID || keys[1].ID != first.ID {
+		t.Fatalf("unexpected listing %+v", keys)
+	}
+
+	if err := svc.RevokeAPIKey(mine, other.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("revoked another user's key: %v", err)
+	}
+	if err := svc.RevokeAPIKey(mine, first.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := svc.VerifyAPIKey(context.Background(), first.Key); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("revoked key still works: %v", err)
+	}
+}
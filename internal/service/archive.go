@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// parseArchivedFilter reads ?archived=exclude|include|only and the
+// ?include_archived=true shorthand.
+func parseArchivedFilter(archived, includeArchived string) (repository.ArchivedFilter, error) {
+	include := false
+	if includeArchived != "" {
+		var err error
+		if include, err = strconv.ParseBool(includeArchived); err != nil {
+			return 0, ErrInvalidFilter
+		}
+	}
+
+	switch archived {
+	case "":
+		if include {
+			return repository.ArchivedInclude, nil
+		}
+		return repository.ArchivedExclude, nil
+	case "exclude":
+		return repository.ArchivedExclude, nil
+	case "include":
+		return repository.ArchivedInclude, nil
+	case "only":
+		return repository.ArchivedOnly, nil
+	}
+	return 0, ErrInvalidFilter
+}
+
+// ArchiveTask hides a task from default listings without deleting it.
+// Archiving an archived task is a no-op.
+func (s *TaskService) ArchiveTask(ctx context.Context, id string, ifMatch VersionMatch) (models.Task, error) {
+	return s.setArchived(ctx, id, true, ifMatch)
+}
+
+func (s *TaskService) UnarchiveTask(ctx context.Context, id string, ifMatch VersionMatch) (models.Task, error) {
+	return s.setArchived(ctx, id, false, ifMatch)
+}
+
+func (s *TaskService) setArchived(ctx context.Context, id string, archived bool, ifMatch VersionMatch) (models.Task, error) {
+	if err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return models.Task{}, err
+	}
+	existing, err := s.getTask(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := checkVersion(existing, ifMatch); err != nil {
+		return models.Task{}, err
+	}
+	if (existing.ArchivedAt != nil) == archived {
+		return *existing, nil
+	}
+
+	existing.ArchivedAt = nil
+	if archived {
+		now := time.Now().UTC()
+		existing.ArchivedAt = &now
+	}
+
+	updated, err := s.repo.Update(ctx, existing)
+	if err != nil {
+		return models.Task{}, mapUpdateErr(err)
+	}
+	return *updated, nil
+}
+
+// AutoArchive archives tasks that have sat in a closed status for longer than olderThan.
+func (s *TaskService) AutoArchive(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return s.repo.ArchiveClosed(ctx, time.Now().Add(-olderThan))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func TestArchiveTask_HiddenFromListsByDefault(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	archived := createChild(t, svc, "archived", nil)
+	createChild(t, svc, "active", nil)
+
+	got, err := svc.ArchiveTask(ctx, archived.ID, nil)
+	if err != nil || got.ArchivedAt == nil {
+		t.Fatalf("ArchiveTask: %+v (err %v)", got, err)
+	}
+	if again, _ := svc.ArchiveTask(ctx, archived.ID, nil); again.Version != got.Version {
+		t.Fatalf("expected archiving twice to be a no-op")
+	}
+
+	cases := []struct {
+		opts ListOptions
+		want int
+	}{
+		{ListOptions{}, 1},
+		{ListOptions{IncludeArchived: "true"}, 2},
+		{ListOptions{Archived: "only"}, 1},
+	}
+	for _, c := range cases {
+		page, err := svc.ListTasks(ctx, c.opts)
+		if err != nil || page.Total != c.want {
+			t.Errorf("ListTasks(%+v): expected %d tasks, got %+v (err %v)", c.opts, c.want, page, err)
+		}
+	}
+	if _, err := svc.ListTasks(ctx, ListOptions{Archived: "maybe"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected bad archived filter to be rejected, got %v", err)
+	}
+
+	if _, err := svc.GetTask(ctx, archived.ID); err != nil {
+		t.Fatalf("expected archived task to stay reachable by id, got %v", err)
+	}
+	restored, err := svc.UnarchiveTask(ctx, archived.ID, VersionMatch{got.Version})
+	if err != nil || restored.ArchivedAt != nil {
+		t.Fatalf("UnarchiveTask: %+v (err %v)", restored, err)
+	}
+}
+
+func TestAutoArchive_CustomClosedStatus(t *testing.T) {
+	ctx := context.Background()
+	statuses := newFakeStatusRepo()
+	if _, err := statuses.Create(ctx, &models.StatusDefinition{Name: "cancelled", Category: models.CategoryClosed, Position: 3}); err != nil {
+		t.Fatalf("create status: %v", err)
+	}
+	repo := newFakeTaskRepo()
+	repo.statuses = statuses
+	svc := NewTaskService(repo, WithStatusCatalog(statuses))
+
+	task := createChild(t, svc, "dropped", nil)
+	if _, err := setStatus(svc, task.ID, models.StatusInProgress, false); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := setStatus(svc, task.ID, "cancelled", false); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	stale := repo.store[task.ID]
+	completed := time.Now().Add(-40 * 24 * time.Hour)
+	stale.CompletedAt = &completed
+	repo.store[task.ID] = stale
+
+	n, err := svc.AutoArchive(ctx, 30*24*time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("expected the cancelled task to be archived, got %d (err %v)", n, err)
+	}
+}
+
+func TestAutoArchive_OnlyOldClosedTasks(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+	ctx := context.Background()
+
+	old := createChild(t, svc, "old", nil)
+	recent := createChild(t, svc, "recent", nil)
+	for _, task := range []*models.Task{old, recent} {
+		if _, err := setStatus(svc, task.ID, models.StatusInProgress, false); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		if _, err := setStatus(svc, task.ID, models.StatusDone, false); err != nil {
+			t.Fatalf("finish: %v", err)
+		}
+	}
+	stale := repo.store[old.ID]
+	completed := time.Now().Add(-40 * 24 * time.Hour)
+	stale.CompletedAt = &completed
+	repo.store[old.ID] = stale
+
+	n, err := svc.AutoArchive(ctx, 30*24*time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one task to be archived, got %d (err %v)", n, err)
+	}
+	if got, _ := svc.GetTask(ctx, recent.ID); got.ArchivedAt != nil {
+		t.Fatalf("expected recently closed task to stay active")
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxAttachmentSize caps a single upload at 25 MiB.
+const DefaultMaxAttachmentSize int64 = 25 << 20
+
+// DefaultAttachmentTypes covers screenshots, logs and common archives. An
+// entry like "image/*" allows a whole family.
+var DefaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"text/plain", "application/pdf", "application/zip", "application/x-gzip",
+}
+
+// orphanBatch is how many orphaned blobs one sweep removes at most.
+const orphanBatch = 100
+
+var (
+	ErrInvalidUpload      = errors.New("upload must be multipart/form-data with a named file in the \"file\" field")
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("attachment type is not allowed")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// AttachmentLimits bounds what can be uploaded; zero values fall back to
+// the defaults above.
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+type UploadInput struct {
+	Filename string
+	Body     io.Reader
+}
+
+type AttachmentService struct {
+	attachments repository.AttachmentRepository
+	tasks       *TaskService
+	blobs       repository.BlobStore
+	limits      AttachmentLimits
+}
+
+func NewAttachmentService(attachments repository.AttachmentRepository, tasks *TaskService,
+	blobs repository.BlobStore, limits AttachmentLimits) *AttachmentService {
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = DefaultMaxAttachmentSize
+	}
+	if len(limits.AllowedTypes) == 0 {
+		limits.AllowedTypes = DefaultAttachmentTypes
+	}
+	return &AttachmentService{
+		attachments: attachments,
+		tasks:       tasks,
+		blobs:       blobs,
+		limits:      limits,
+	}
+}
+
+func (s *AttachmentService) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+// cleanFilename keeps the last path element and drops control characters,
+// since the name ends up in a Content-Disposition header.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "." || name == "/" || len(name) > 255 {
+		return ""
+	}
+	return name
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range s.limits.AllowedTypes {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// Upload stores a file for a task. The body is spooled to a temporary file
+// so its size, checksum and sniffed content type are known before anything
+// reaches the blob store; the client's declared type is not trusted.
+func (s *AttachmentService) Upload(ctx context.Context, taskID string, in UploadInput) (*models.Attachment, error) {
+	filename := cleanFilename(in.Filename)
+	if filename == "" || in.Body == nil {
+		return nil, ErrInvalidUpload
+	}
+	if err := s.checkTask(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "taskapi-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, sum), io.LimitReader(in.Body, s.limits.MaxSize+1))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || size > s.limits.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !s.allowed(contentType) {
+		return nil, ErrAttachmentType
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	key := "tasks/" + taskID + "/" + uuid.NewString()
+	if err := s.blobs.Put(ctx, key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	a, err := s.attachments.Create(ctx, &models.Attachment{
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(sum.Sum(nil)),
+		StorageKey:  key,
+		UploadedBy:  audit.Actor(ctx),
+	})
+	if err != nil {
+		_ = s.blobs.Delete(ctx, key)
+		return nil, mapAttachmentErr(err)
+	}
+	return a, nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, taskID string) ([]models.Attachment, error) {
+	if err := s.checkTask(ctx, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.attachments.List(ctx, taskID)
+}
+
+// Open returns an attachment's metadata with a reader over its bytes; the
+// caller closes the reader.
+func (s *AttachmentService) Open(ctx context.Context, taskID, id string) (*models.Attachment, io.ReadCloser, error) {
+	if err := s.checkTask(ctx, taskID, models.RoleViewer); err != nil {
+		return nil, nil, err
+	}
+	a, err := s.attachments.GetByID(ctx, taskID, id)
+	if err != nil {
+		return nil, nil, mapAttachmentErr(err)
+	}
+	body, err := s.blobs.Get(ctx, a.StorageKey)
+	if err != nil {
+		return nil, nil, mapAttachmentErr(err)
+	}
+	return a, body, nil
+}
+
+// DeleteAttachment drops the metadata and then the blob. If the blob can't
+// be removed right away it stays queued for SweepOrphanedBlobs.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, taskID, id string) error {
+	if err := s.checkTask(ctx, taskID, models.RoleEditor); err != nil {
+		return err
+	}
+	a, err := s.attachments.GetByID(ctx, taskID, id)
+	if err != nil {
+		return mapAttachmentErr(err)
+	}
+	if err := s.attachments.Delete(ctx, taskID, id); err != nil {
+		return mapAttachmentErr(err)
+	}
+
+	if err := s.blobs.Delete(ctx, a.StorageKey); err == nil {
+		_ = s.attachments.ForgetBlobs(ctx, []string{a.StorageKey})
+	}
+	return nil
+}
+
+// SweepOrphanedBlobs removes blobs left behind by deleted attachments and
+// purged tasks, and reports how many went.
+func (s *AttachmentService) SweepOrphanedBlobs(ctx context.Context) (int, error) {
+	keys, err := s.attachments.OrphanedBlobs(ctx, orphanBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var done []string
+	var errs []error
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		done = append(done, key)
+	}
+	if len(done) > 0 {
+		if err := s.attachments.ForgetBlobs(ctx, done); err != nil {
+			return 0, err
+		}
+	}
+	return len(done), errors.Join(errs...)
+}
+
+// checkTask hides the attachments of trashed and missing tasks, and of
+// tasks the caller can't see, and checks the caller holds need on the task.
+func (s *AttachmentService) checkTask(ctx context.Context, taskID string, need models.Role) error {
+	_, err := s.tasks.authorizedTask(ctx, taskID, need)
+	return err
+}
+
+func mapAttachmentErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrAttachmentNotFound), errors.Is(err, models.ErrBlobNotFound):
+		return ErrAttachmentNotFound
+	case errors.Is(err, models.ErrNotFound):
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeAttachmentRepo struct {
+	rows     []models.Attachment
+	orphaned []string
+}
+
+func (f *fakeAttachmentRepo) Create(_ context.Context, a *models.Attachment) (*models.Attachment, error) {
+	copy := *a
+	copy.ID = fmt.Sprintf("a%d", len(f.rows)+1)
+	copy.CreatedAt = time.Now()
+	f.rows = append(f.rows, copy)
+	return &copy, nil
+}
+
+func (f *fakeAttachmentRepo) GetByID(_ context.Context, taskID, id string) (*models.Attachment, error) {
+	for _, a := range f.rows {
+		if a.ID == id && a.TaskID == taskID {
+			return &a, nil
+		}
+	}
+	return nil, models.ErrAttachmentNotFound
+}
+
+func (f *fakeAttachmentRepo) List(_ context.Context, taskID string) ([]models.Attachment, error) {
+	out := []models.Attachment{}
+	for _, a := range f.rows {
+		if a.TaskID == taskID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// Delete queues the blob like the database trigger does.
+func (f *fakeAttachmentRepo) Delete(_ context.Context, taskID, id string) error {
+	for i, a := range f.rows {
+		if a.ID == id && a.TaskID == taskID {
+			f.rows = append(f.rows[:i], f.rows[i+1:]...)
+			f.orphaned = append(f.orphaned, a.StorageKey)
+			return nil
+		}
+	}
+	return models.ErrAttachmentNotFound
+}
+
+func (f *fakeAttachmentRepo) OrphanedBlobs(_ context.Context, limit int) ([]string, error) {
+	return f.orphaned[:min(limit, len(f.orphaned))], nil
+}
+
+func (f *fakeAttachmentRepo) ForgetBlobs(_ context.Context, keys []string) error {
+	var keep []string
+	for _, k := range f.orphaned {
+		if !slices.Contains(keys, k) {
+			keep = append(keep, k)
+		}
+	}
+	f.orphaned = keep
+	return nil
+}
+
+type memBlobStore struct {
+	blobs map[string][]byte
+	// failDeletes simulates an unreachable store
+	failDeletes bool
+}
+
+func (m *memBlobStore) Put(_ context.Context, key string, r io.Reader, size int64, _ string) error {
+	b, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	m.blobs[key] = b
+	return nil
+}
+
+func (m *memBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	b, ok := m.blobs[key]
+	if !ok {
+		return nil, models.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m *memBlobStore) Delete(_ context.Context, key string) error {
+	if m.failDeletes {
+		return errors.New("store unavailable")
+	}
+	delete(m.blobs, key)
+	return nil
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newAttachmentFixture(t *testing.T, limits AttachmentLimits) (*AttachmentService, *fakeAttachmentRepo, *memBlobStore, *models.Task) {
+	t.Helper()
+	tasks := newFakeTaskRepo()
+	task := createChild(t, NewTaskService(tasks), "has files", nil)
+	repo := &fakeAttachmentRepo{}
+	blobs := &memBlobStore{blobs: map[string][]byte{}}
+	return NewAttachmentService(repo, NewTaskService(tasks), blobs, limits), repo, blobs, task
+}
+
+func TestUpload_SniffsTypeAndStoresBlob(t *testing.T) {
+	svc, _, _, task := newAttachmentFixture(t, AttachmentLimits{})
+	ctx := context.Background()
+
+	a, err := svc.Upload(ctx, task.ID, UploadInput{Filename: `C:\shots\screen.png`, Body: bytes.NewReader(pngHeader)})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if a.Filename != "screen.png" || a.ContentType != "image/png" || a.Size != int64(len(pngHeader)) || a.SHA256 == "" {
+		t.Fatalf("unexpected attachment: %+v", a)
+	}
+
+	got, body, err := svc.Open(ctx, task.ID, a.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer body.Close()
+	b, _ := io.ReadAll(body)
+	if got.ID != a.ID || !bytes.Equal(b, pngHeader) {
+		t.Fatalf("Open returned %+v with %q", got, b)
+	}
+}
+
+func TestUpload_Limits(t *testing.T) {
+	svc, _, blobs, task := newAttachmentFixture(t, AttachmentLimits{MaxSize: 16, AllowedTypes: []string{"image/*"}})
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		in   UploadInput
+		want error
+	}{
+		{"too large", UploadInput{Filename: "big.png", Body: bytes.NewReader(append(pngHeader, make([]byte, 16)...))}, ErrAttachmentTooLarge},
+		{"html disguised as png", UploadInput{Filename: "x.png", Body: strings.NewReader("<html><script>")}, ErrAttachmentType},
+		{"text not allowed", UploadInput{Filename: "app.log", Body: strings.NewReader("boot ok")}, ErrAttachmentType},
+		{"no filename", UploadInput{Filename: "", Body: bytes.NewReader(pngHeader)}, ErrInvalidUpload},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Upload(ctx, task.ID, tc.in); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if len(blobs.blobs) != 0 {
+		t.Fatalf("expected rejected uploads to store nothing, got %d blobs", len(blobs.blobs))
+	}
+	if _, err := svc.Upload(ctx, "missing", UploadInput{Filename: "a.png", Body: bytes.NewReader(pngHeader)}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing task, got %v", err)
+	}
+}
+
+func TestDeleteAttachment_SweepsBlobsLeftBehind(t *testing.T) {
+	svc, repo, blobs, task := newAttachmentFixture(t, AttachmentLimits{})
+	ctx := context.Background()
+
+	a, err := svc.Upload(ctx, task.ID, UploadInput{Filename: "a.png", Body: bytes.NewReader(pngHeader)})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	blobs.failDeletes = true
+	if err := svc.DeleteAttachment(ctx, task.ID, a.ID); err != nil {
+		t.Fatalf("DeleteAttachment: %v", err)
+	}
+	if _, _, err := svc.Open(ctx, task.ID, a.ID); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Fatalf("expected the attachment to be gone, got %v", err)
+	}
+	if len(repo.orphaned) != 1 || len(blobs.blobs) != 1 {
+		t.Fatalf("expected the blob to stay queued, got %v", repo.orphaned)
+	}
+
+	blobs.failDeletes = false
+	n, err := svc.SweepOrphanedBlobs(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 swept blob, got %d (err %v)", n, err)
+	}
+	if len(repo.orphaned) != 0 || len(blobs.blobs) != 0 {
+		t.Fatalf("expected the sweep to clear everything, got %v / %d blobs", repo.orphaned, len(blobs.blobs))
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// MaxBatchSize caps the operations accepted by one batch request.
+const MaxBatchSize = 500
+
+var ErrInvalidBatch = errors.New("batch is invalid")
+
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+type BatchMode string
+
+const (
+	// BatchAtomic applies every operation or none of them
+	BatchAtomic BatchMode = "atomic"
+	// BatchPerItem keeps the operations that succeed and reports the rest
+	BatchPerItem BatchMode = "per_item"
+)
+
+type BatchOperation struct {
+	Op BatchOp `json:"op"`
+	// ID is the target of update and delete
+	ID string `json:"id"`
+	// Data is a CreateTaskInput or a partial UpdateTaskInput
+	Data json.RawMessage `json:"data"`
+	// IfMatch makes an update or delete conditional on the task's version
+	IfMatch *int `json:"if_match"`
+	// Children is the delete policy, see DeleteOptions
+	Children ChildPolicy `json:"children"`
+}
+
+type BatchInput struct {
+	Mode       BatchMode
+	Operations []BatchOperation
+}
+
+// BatchResult reports one operation; Task is nil for deletes and failures.
+type BatchResult struct {
+	Op   BatchOp
+	Task *models.Task
+	Err  error
+}
+
+// Batch runs the operations in order inside one transaction. In atomic
+// mode the first failure rolls everything back and is returned as the
+// error; in per-item mode each operation gets its own savepoint and its
+// outcome is reported in the matching result.
+func (s *TaskService) Batch(ctx context.Context, in BatchInput) ([]BatchResult, error) {
+	mode := in.Mode
+	if mode == "" {
+		mode = BatchAtomic
+	}
+	if mode != BatchAtomic && mode != BatchPerItem {
+		return nil, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidBatch, BatchAtomic, BatchPerItem)
+	}
+	if len(in.Operations) == 0 || len(in.Operations) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: between 1 and %d operations are required", ErrInvalidBatch, MaxBatchSize)
+	}
+
+	results := make([]BatchResult, len(in.Operations))
+	err := s.repo.WithTx(ctx, func(tx repository.TaskRepository) error {
+		for i, op := range in.Operations {
+			results[i].Op = op.Op
+
+			if mode == BatchAtomic {
+				task, err := s.withRepo(tx).applyBatchOp(ctx, op)
+				if err != nil {
+					return fmt.Errorf("operation %d: %w", i, err)
+				}
+				results[i].Task = task
+				continue
+			}
+
+			results[i].Err = tx.WithTx(ctx, func(item repository.TaskRepository) error {
+				task, err := s.withRepo(item).applyBatchOp(ctx, op)
+				results[i].Task = task
+				return err
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// withRepo is a copy of the service that talks to r, e.g. inside a transaction.
+func (s *TaskService) withRepo(r repository.TaskRepository) *TaskService {
+	cp := *s
+	cp.repo = r
+	return &cp
+}
+
+func (s *TaskService) applyBatchOp(ctx context.Context, op BatchOperation) (*models.Task, error) {
+	var ifMatch VersionMatch
+	if op.IfMatch != nil {
+		ifMatch = VersionMatch{*op.IfMatch}
+	}
+
+	switch op.Op {
+	case BatchCreate:
+		var in CreateTaskInput
+		if err := decodeBatchData(op.Data, &in); err != nil {
+			return nil, err
+		}
+		task, err := s.CreateTask(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		return task, nil
+	case BatchUpdate:
+		var in UpdateTaskInput
+		if err := decodeBatchData(op.Data, &in); err != nil {
+			return nil, err
+		}
+		in.IfMatch = ifMatch
+		task, err := s.UpdateTask(ctx, op.ID, in)
+		if err != nil {
+			return nil, err
+		}
+		return &task, nil
+	case BatchDelete:
+		return nil, s.DeleteTask(ctx, op.ID, DeleteOptions{Children: op.Children, IfMatch: ifMatch})
+	}
+	return nil, fmt.Errorf("%w: op must be create, update or delete", ErrInvalidBatch)
+}
+
+// decodeBatchData rejects unknown fields, like the single-task endpoints.
+func decodeBatchData(data json.RawMessage, dst any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: data is required", ErrInvalidBatch)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return fmt.Errorf("%w: data: %v", ErrInvalidBatch, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func sprintOps() []BatchOperation {
+	return []BatchOperation{
+		{Op: BatchCreate, Data: json.RawMessage(`{"title": "first"}`)},
+		{Op: BatchCreate, Data: json.RawMessage(`{"title": "second", "priority": 3}`)},
+		{Op: BatchUpdate, ID: "missing", Data: json.RawMessage(`{"title": "renamed"}`)},
+	}
+}
+
+func TestBatch_AtomicRollsBack(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	_, err := svc.Batch(context.Background(), BatchInput{Operations: sprintOps()})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the failing update to abort the batch, got %v", err)
+	}
+	if len(repo.store) != 0 {
+		t.Fatalf("expected nothing to be stored, got %d tasks", len(repo.store))
+	}
+}
+
+func TestBatch_PerItemKeepsSuccesses(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	results, err := svc.Batch(context.Background(), BatchInput{Mode: BatchPerItem, Operations: sprintOps()})
+	if err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if results[0].Err != nil || results[1].Err != nil || results[1].Task.Priority != 3 {
+		t.Fatalf("expected both creates to succeed, got %+v", results)
+	}
+	if !errors.Is(results[2].Err, ErrNotFound) || results[2].Task != nil {
+		t.Fatalf("expected the update to report not found, got %+v", results[2])
+	}
+	if len(repo.store) != 2 {
+		t.Fatalf("expected 2 stored tasks, got %d", len(repo.store))
+	}
+}
+
+func TestBatch_Validation(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+
+	cases := []BatchInput{
+		{},
+		{Mode: "best_effort", Operations: sprintOps()},
+		{Operations: []BatchOperation{{Op: "upsert"}}},
+		{Operations: []BatchOperation{{Op: BatchCreate}}},
+		{Operations: []BatchOperation{{Op: BatchCreate, Data: json.RawMessage(`{"title": "x", "titel": "typo"}`)}}},
+	}
+	for _, in := range cases {
+		if _, err := svc.Batch(ctx, in); !errors.Is(err, ErrInvalidBatch) {
+			t.Errorf("Batch(%+v): expected ErrInvalidBatch, got %v", in, err)
+		}
+	}
+}
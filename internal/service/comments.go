@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// DefaultCommentEditWindow is how long after posting a comment can still
+// be edited.
+const DefaultCommentEditWindow = 15 * time.Minute
+
+const maxCommentLength = 10000
+
+var (
+	ErrInvalidComment    = errors.New("comment body is required and must be <= 10000 characters")
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrEditWindowExpired = errors.New("comment can no longer be edited")
+)
+
+type CommentInput struct {
+	Body string `json:"body"`
+}
+
+// CommentPage is one page of a task's comments, oldest first.
+type CommentPage struct {
+	Comments []models.Comment
+	Total    int
+	Page     int
+	PageSize int
+	HasMore  bool
+}
+
+type CommentService struct {
+	comments repository.CommentRepository
+	tasks    *TaskService
+	// editWindow <= 0 leaves comments editable forever
+	editWindow time.Duration
+}
+
+func NewCommentService(comments repository.CommentRepository, tasks *TaskService, editWindow time.Duration) *CommentService {
+	return &CommentService{
+		comments:   comments,
+		tasks:      tasks,
+		editWindow: editWindow,
+	}
+}
+
+func normalizeCommentBody(body string) (string, error) {
+	b := strings.TrimSpace(body)
+	if b == "" || utf8.RuneCountInString(b) > maxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return b, nil
+}
+
+func (s *CommentService) ListComments(ctx context.Context, taskID, page, pageSize string) (*CommentPage, error) {
+	if err := s.checkTask(ctx, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	pp, err := parsePage(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.comments.Count(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.comments.List(ctx, taskID, pp.pagination())
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(comments) > pp.Size
+	if hasMore {
+		comments = comments[:pp.Size]
+	}
+
+	return &CommentPage{
+		Comments: comments,
+		Total:    total,
+		Page:     pp.Page,
+		PageSize: pp.Size,
+		HasMore:  hasMore,
+	}, nil
+}
+
+// CreateComment posts a comment as the request's actor.
+func (s *CommentService) CreateComment(ctx context.Context, taskID string, in CommentInput) (*models.Comment, error) {
+	body, err := normalizeCommentBody(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTask(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	c, err := s.comments.Create(ctx, &models.Comment{
+		TaskID: taskID,
+		Author: audit.Actor(ctx),
+		Body:   body,
+	})
+	if err != nil {
+		return nil, mapCommentErr(err)
+	}
+	return c, nil
+}
+
+// UpdateComment rewrites the body while the edit window is open. Only the
+// author may edit a comment.
+func (s *CommentService) UpdateComment(ctx context.Context, taskID, id string, in CommentInput) (*models.Comment, error) {
+	body, err := normalizeCommentBody(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTask(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.comments.GetByID(ctx, taskID, id)
+	if err != nil {
+		return nil, mapCommentErr(err)
+	}
+	if existing.Author != audit.Actor(ctx) {
+		return nil, ErrForbidden
+	}
+	if s.editWindow > 0 && time.Since(existing.CreatedAt) > s.editWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	existing.Body = body
+	c, err := s.comments.Update(ctx, existing)
+	if err != nil {
+		return nil, mapCommentErr(err)
+	}
+	return c, nil
+}
+
+// DeleteComment removes a comment, however old. Authors may delete their
+// own comments, and owners of the task anyone's.
+func (s *CommentService) DeleteComment(ctx context.Context, taskID, id string) error {
+	if err := s.checkTask(ctx, taskID, models.RoleEditor); err != nil {
+		return err
+	}
+
+	existing, err := s.comments.GetByID(ctx, taskID, id)
+	if err != nil {
+		return mapCommentErr(err)
+	}
+	if existing.Author != audit.Actor(ctx) {
+		if err := s.tasks.Authorize(ctx, taskID, models.RoleOwner); err != nil {
+			return err
+		}
+	}
+	return mapCommentErr(s.comments.Delete(ctx, taskID, id))
+}
+
+// checkTask hides the comments of trashed and missing tasks, and of tasks
+// the caller can't see, and checks the caller holds need on the task.
+func (s *CommentService) checkTask(ctx context.Context, taskID string, need models.Role) error {
+	_, err := s.tasks.authorizedTask(ctx, taskID, need)
+	return err
+}
+
+func mapCommentErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrCommentNotFound):
+		return ErrCommentNotFound
+	case errors.Is(err, models.ErrNotFound):
+		return ErrNotFound
+	}
+	return err
+}
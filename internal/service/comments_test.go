@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeCommentRepo struct {
+	comments []models.Comment
+}
+
+func (f *fakeCommentRepo) Create(_ context.Context, c *models.Comment) (*models.Comment, error) {
+	copy := *c
+	copy.ID = fmt.Sprintf("c%d", len(f.comments)+1)
+	copy.CreatedAt = time.Now()
+	copy.UpdatedAt = copy.CreatedAt
+	f.comments = append(f.comments, copy)
+	return &copy, nil
+}
+
+func (f *fakeCommentRepo) find(taskID, id string) int {
+	for i, c := range f.comments {
+		if c.ID == id && c.TaskID == taskID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeCommentRepo) GetByID(_ context.Context, taskID, id string) (*models.Comment, error) {
+	i := f.find(taskID, id)
+	if i < 0 {
+		return nil, models.ErrCommentNotFound
+	}
+	copy := f.comments[i]
+	return &copy, nil
+}
+
+func (f *fakeCommentRepo) List(_ context.Context, taskID string, p repository.Pagination) ([]models.Comment, error) {
+	out := []models.Comment{}
+	for _, c := range f.comments {
+		if c.TaskID == taskID {
+			out = append(out, c)
+		}
+	}
+	if p.Offset >= len(out) {
+		return []models.Comment{}, nil
+	}
+	out = out[p.Offset:]
+	if p.Limit > 0 && len(out) > p.Limit {
+		out = out[:p.Limit]
+	}
+	return out, nil
+}
+
+func (f *fakeCommentRepo) Count(ctx context.Context, taskID string) (int, error) {
+	all, _ := f.List(ctx, taskID, repository.Pagination{})
+	return len(all), nil
+}
+
+func (f *fakeCommentRepo) Update(_ context.Context, c *models.Comment) (*models.Comment, error) {
+	i := f.find(c.TaskID, c.ID)
+	if i < 0 {
+		return nil, models.ErrCommentNotFound
+	}
+	f.comments[i].Body = c.Body
+	f.comments[i].UpdatedAt = time.Now()
+	copy := f.comments[i]
+	return &copy, nil
+}
+
+func (f *fakeCommentRepo) Delete(_ context.Context, taskID, id string) error {
+	i := f.find(taskID, id)
+	if i < 0 {
+		return models.ErrCommentNotFound
+	}
+	f.comments = append(f.comments[:i], f.comments[i+1:]...)
+	return nil
+}
+
+func TestCreateComment_ValidatesAndStampsAuthor(t *testing.T) {
+	tasks := newFakeTaskRepo()
+	task := createChild(t, NewTaskService(tasks), "discuss me", nil)
+	svc := NewCommentService(&fakeCommentRepo{}, NewTaskService(tasks), DefaultCommentEditWindow)
+	ctx := audit.WithActor(context.Background(), "alice")
+
+	for _, body := range []string{"", "   ", strings.Repeat("x", maxCommentLength+1)} {
+		if _, err := svc.CreateComment(ctx, task.ID, CommentInput{Body: body}); !errors.Is(err, ErrInvalidComment) {
+			t.Fatalf("body of %d chars: expected ErrInvalidComment, got %v", len(body), err)
+		}
+	}
+
+	c, err := svc.CreateComment(ctx, task.ID, CommentInput{Body: "  looks good  "})
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if c.Body != "looks good" || c.Author != "alice" {
+		t.Fatalf("unexpected comment: %+v", c)
+	}
+	if _, err := svc.CreateComment(ctx, "missing", CommentInput{Body: "hi"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing task, got %v", err)
+	}
+}
+
+func TestUpdateComment_EditWindow(t *testing.T) {
+	tasks := newFakeTaskRepo()
+	task := createChild(t, NewTaskService(tasks), "discuss me", nil)
+	comments := &fakeCommentRepo{}
+	svc := NewCommentService(comments, NewTaskService(tasks), time.Minute)
+	ctx := context.Background()
+
+	c, err := svc.CreateComment(ctx, task.ID, CommentInput{Body: "first"})
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	got, err := svc.UpdateComment(ctx, task.ID, c.ID, CommentInput{Body: "edited"})
+	if err != nil || got.Body != "edited" {
+		t.Fatalf("expected edit inside the window, got %+v (err %v)", got, err)
+	}
+
+	comments.comments[0].CreatedAt = time.Now().Add(-2 * time.Minute)
+	if _, err := svc.UpdateComment(ctx, task.ID, c.ID, CommentInput{Body: "too late"}); !errors.Is(err, ErrEditWindowExpired) {
+		t.Fatalf("expected ErrEditWindowExpired, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, task.ID, c.ID); err != nil {
+		t.Fatalf("expected delete to ignore the edit window, got %v", err)
+	}
+}
+
+func TestComments_ScopedToTask(t *testing.T) {
+	tasks := newFakeTaskRepo()
+	taskSvc := NewTaskService(tasks)
+	a := createChild(t, taskSvc, "a", nil)
+	b := createChild(t, taskSvc, "b", nil)
+	svc := NewCommentService(&fakeCommentRepo{}, NewTaskService(tasks), 0)
+	ctx := context.Background()
+
+	c, err := svc.CreateComment(ctx, a.ID, CommentInput{Body: "on a"})
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if _, err := svc.UpdateComment(ctx, b.ID, c.ID, CommentInput{Body: "hijack"}); !errors.Is(err, ErrCommentNotFound) {
+		t.Fatalf("expected ErrCommentNotFound through another task, got %v", err)
+	}
+
+	page, err := svc.ListComments(ctx, b.ID, "", "")
+	if err != nil || page.Total != 0 {
+		t.Fatalf("expected no comments on b, got %+v (err %v)", page, err)
+	}
+
+	if err := taskSvc.DeleteTask(ctx, a.ID, DeleteOptions{}); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := svc.ListComments(ctx, a.ID, "", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected comments of a trashed task to be hidden, got %v", err)
+	}
+}
+
+func TestComments_OnlyAuthorsEditAndAuthorsOrOwnersDelete(t *testing.T) {
+	taskSvc := newAccessControlledService()
+	svc := NewCommentService(&fakeCommentRepo{}, taskSvc, 0)
+	// the HTTP middleware stamps the signed-in user as the audit actor
+	as := func(user string) context.Context { return audit.WithActor(sessionCtx(user), user) }
+	alice, bob, carol := as("alice"), as("bob"), as("carol")
+
+	task, err := taskSvc.CreateTask(alice, CreateTaskInput{Title: "discuss me"})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	for _, email := range []string{"bob@example.com", "carol@example.com"} {
+		if _, err := taskSvc.SetMember(alice, task.ID, MemberInput{Email: email, Role: models.RoleEditor}); err != nil {
+			t.Fatalf("set member err: %v", err)
+		}
+	}
+
+	byBob, err := svc.CreateComment(bob, task.ID, CommentInput{Body: "bob's"})
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if _, err := svc.UpdateComment(carol, task.ID, byBob.ID, CommentInput{Body: "carol's now"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden editing someone else's comment, got %v", err)
+	}
+	if _, err := svc.UpdateComment(alice, task.ID, byBob.ID, CommentInput{Body: "alice's now"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for an owner editing someone else's comment, got %v", err)
+	}
+	if got, err := svc.UpdateComment(bob, task.ID, byBob.ID, CommentInput{Body: "edited"}); err != nil || got.Body != "edited" {
+		t.Fatalf("expected the author's edit, got %+v (err %v)", got, err)
+	}
+
+	if err := svc.DeleteComment(carol, task.ID, byBob.ID); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for an editor deleting someone else's comment, got %v", err)
+	}
+	if err := svc.DeleteComment(bob, task.ID, byBob.ID); err != nil {
+		t.Fatalf("expected the author's delete, got %v", err)
+	}
+
+	byCarol, err := svc.CreateComment(carol, task.ID, CommentInput{Body: "carol's"})
+	if err != nil {
+		t.Fatalf("CreateComment: %v", err)
+	}
+	if err := svc.DeleteComment(alice, task.ID, byCarol.ID); err != nil {
+		t.Fatalf("expected the owner's delete, got %v", err)
+	}
+}
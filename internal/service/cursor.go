@@ -0,0 +1,82 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+type cursorPayload struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Dir       string    `json:"d"`
+}
+
+// cursorCodec signs keyset positions so clients can't forge or tamper with them.
+type cursorCodec struct {
+	secret []byte
+}
+
+func newCursorCodec(secret []byte) cursorCodec {
+	if len(secret) == 0 {
+		// Tokens won't survive a restart, but they stay unforgeable
+		secret = make([]byte, 32)
+		_, _ = rand.Read(secret)
+	}
+	return cursorCodec{secret: secret}
+}
+
+func (c cursorCodec) encode(t models.Task, dir string) string {
+	raw, _ := json.Marshal(cursorPayload{CreatedAt: t.CreatedAt, ID: t.ID, Dir: dir})
+	body := base64.RawURLEncoding.EncodeToString(raw)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(body))
+}
+
+func (c cursorCodec) decode(token string) (cursorPayload, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursorPayload{}, ErrInvalidCursor
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, c.sign(body)) {
+		return cursorPayload{}, ErrInvalidCursor
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return cursorPayload{}, ErrInvalidCursor
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return cursorPayload{}, ErrInvalidCursor
+	}
+	if p.ID == "" || (p.Dir != cursorNext && p.Dir != cursorPrev) {
+		return cursorPayload{}, ErrInvalidCursor
+	}
+	return p, nil
+}
+
+func (c cursorCodec) sign(body string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
+
+func (p cursorPayload) toRepo() *repository.Cursor {
+	return &repository.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
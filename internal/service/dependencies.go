@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var (
+	ErrDependencyCycle         = errors.New("dependency would create a cycle")
+	ErrDependencyGraphTooLarge = errors.New("dependency graph is too large to check for cycles")
+	ErrDependencyNotFound      = errors.New("dependency not found")
+	ErrBlocked                 = errors.New("task has unfinished blockers")
+)
+
+type DependencyService struct {
+	deps  repository.DependencyRepository
+	tasks *TaskService
+}
+
+func NewDependencyService(deps repository.DependencyRepository, tasks *TaskService) *DependencyService {
+	return &DependencyService{
+		deps:  deps,
+		tasks: tasks,
+	}
+}
+
+// AddBlocker records that taskID can't be finished before blockerID.
+func (s *DependencyService) AddBlocker(ctx context.Context, taskID, blockerID string) (*models.Task, error) {
+	if taskID == blockerID {
+		return nil, ErrDependencyCycle
+	}
+	if err := s.tasks.Authorize(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+	if _, err := s.tasks.GetTask(ctx, blockerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.deps.Add(ctx, taskID, blockerID); err != nil {
+		return nil, mapDependencyErr(err)
+	}
+	return s.tasks.GetTask(ctx, taskID)
+}
+
+func (s *DependencyService) RemoveBlocker(ctx context.Context, taskID, blockerID string) (*models.Task, error) {
+	if err := s.tasks.Authorize(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	if err := s.deps.Remove(ctx, taskID, blockerID); err != nil {
+		return nil, mapDependencyErr(err)
+	}
+	return s.tasks.GetTask(ctx, taskID)
+}
+
+// ListBlockers pages through the tasks blocking taskID.
+func (s *DependencyService) ListBlockers(ctx context.Context, taskID string, in ListOptions) (*TaskPage, error) {
+	if _, err := s.tasks.GetTask(ctx, taskID); err != nil {
+		return nil, err
+	}
+
+	in.BlockersOf = taskID
+	return s.tasks.ListTasks(ctx, in)
+}
+
+func mapDependencyErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrDependencyNotFound):
+		return ErrDependencyNotFound
+	case errors.Is(err, models.ErrDependencyCycle):
+		return ErrDependencyCycle
+	case errors.Is(err, models.ErrDependencyGraphTooLarge):
+		return ErrDependencyGraphTooLarge
+	case errors.Is(err, models.ErrNotFound):
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// fakeDependencyRepo shares its edges with the fake task repo so GetByID
+// can compute Blocked the way the real repositories do.
+type fakeDependencyRepo struct {
+	tasks *fakeTaskRepo
+	// maxVisits overrides repository.MaxDependencyVisits when set
+	maxVisits int
+}
+
+func (f *fakeDependencyRepo) Add(ctx context.Context, taskID, blockerID string) error {
+	limit := repository.MaxDependencyVisits
+	if f.maxVisits > 0 {
+		limit = f.maxVisits
+	}
+	seen := map[string]bool{}
+	stack := slices.Clone(f.tasks.blockers[blockerID])
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur == taskID {
+			return models.ErrDependencyCycle
+		}
+		if seen[cur] {
+			continue
+		}
+		if seen[cur] = true; len(seen) > limit {
+			return models.ErrDependencyGraphTooLarge
+		}
+		stack = append(stack, f.tasks.blockers[cur]...)
+	}
+
+	if !slices.Contains(f.tasks.blockers[taskID], blockerID) {
+		f.tasks.blockers[taskID] = append(f.tasks.blockers[taskID], blockerID)
+	}
+	return nil
+}
+
+func (f *fakeDependencyRepo) Remove(ctx context.Context, taskID, blockerID string) error {
+	i := slices.Index(f.tasks.blockers[taskID], blockerID)
+	if i < 0 {
+		return models.ErrDependencyNotFound
+	}
+	f.tasks.blockers[taskID] = slices.Delete(f.tasks.blockers[taskID], i, i+1)
+	return nil
+}
+
+func newDependencyFixture() (*TaskService, *DependencyService) {
+	repo := newFakeTaskRepo()
+	tasks := NewTaskService(repo)
+	return tasks, NewDependencyService(&fakeDependencyRepo{tasks: repo}, tasks)
+}
+
+// --- TESTS ---
+
+func TestAddBlocker_DetectsCycles(t *testing.T) {
+	tasks, deps := newDependencyFixture()
+	ctx := context.Background()
+
+	a := createChild(t, tasks, "a", nil)
+	b := createChild(t, tasks, "b", nil)
+	c := createChild(t, tasks, "c", nil)
+
+	if _, err := deps.AddBlocker(ctx, a.ID, b.ID); err != nil {
+		t.Fatalf("a blocked by b: %v", err)
+	}
+	if _, err := deps.AddBlocker(ctx, b.ID, c.ID); err != nil {
+		t.Fatalf("b blocked by c: %v", err)
+	}
+
+	if _, err := deps.AddBlocker(ctx, c.ID, a.ID); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected ErrDependencyCycle, got %v", err)
+	}
+	if _, err := deps.AddBlocker(ctx, a.ID, a.ID); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected ErrDependencyCycle for self-dependency, got %v", err)
+	}
+}
+
+func TestAddBlocker_GivesUpOnHugeGraphs(t *testing.T) {
+	repo := newFakeTaskRepo()
+	tasks := NewTaskService(repo)
+	deps := NewDependencyService(&fakeDependencyRepo{tasks: repo, maxVisits: 1}, tasks)
+	ctx := context.Background()
+
+	a := createChild(t, tasks, "a", nil)
+	b := createChild(t, tasks, "b", nil)
+	c := createChild(t, tasks, "c", nil)
+	d := createChild(t, tasks, "d", nil)
+	for _, edge := range [][2]string{{b.ID, c.ID}, {c.ID, d.ID}} {
+		if _, err := deps.AddBlocker(ctx, edge[0], edge[1]); err != nil {
+			t.Fatalf("add blocker: %v", err)
+		}
+	}
+
+	if _, err := deps.AddBlocker(ctx, a.ID, b.ID); !errors.Is(err, ErrDependencyGraphTooLarge) {
+		t.Fatalf("expected ErrDependencyGraphTooLarge, got %v", err)
+	}
+	if _, err := deps.AddBlocker(ctx, a.ID, c.ID); err != nil {
+		t.Fatalf("expected a small enough graph to pass, got %v", err)
+	}
+}
+
+func TestUpdateTask_RefusesDoneWhileBlocked(t *testing.T) {
+	tasks, deps := newDependencyFixture()
+	ctx := context.Background()
+
+	task := createChild(t, tasks, "ship", nil)
+	blocker := createChild(t, tasks, "review", nil)
+
+	got, err := deps.AddBlocker(ctx, task.ID, blocker.ID)
+	if err != nil {
+		t.Fatalf("add blocker: %v", err)
+	}
+	if !got.Blocked {
+		t.Fatalf("expected task to be reported as blocked")
+	}
+
+	inProgress := string(models.StatusInProgress)
+	if _, err := tasks.UpdateTask(ctx, task.ID, UpdateTaskInput{Status: &inProgress}); err != nil {
+		t.Fatalf("start err: %v", err)
+	}
+
+	done := string(models.StatusDone)
+	if _, err := tasks.UpdateTask(ctx, task.ID, UpdateTaskInput{Status: &done}); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+
+	if _, err := tasks.UpdateTask(ctx, task.ID, UpdateTaskInput{Status: &done, IgnoreBlockers: true}); err != nil {
+		t.Fatalf("override should succeed, got %v", err)
+	}
+}
+
+func TestRemoveBlocker_UnknownEdge(t *testing.T) {
+	tasks, deps := newDependencyFixture()
+
+	a := createChild(t, tasks, "a", nil)
+	b := createChild(t, tasks, "b", nil)
+
+	if _, err := deps.RemoveBlocker(context.Background(), a.ID, b.ID); !errors.Is(err, ErrDependencyNotFound) {
+		t.Fatalf("expected ErrDependencyNotFound, got %v", err)
+	}
+}
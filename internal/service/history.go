@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// EventPage is one page of a task's history, newest first.
+type EventPage struct {
+	Events   []models.TaskEvent
+	Total    int
+	Page     int
+	PageSize int
+	HasMore  bool
+}
+
+type HistoryService struct {
+	events repository.TaskEventRepository
+	tasks  *TaskService
+}
+
+func NewHistoryService(events repository.TaskEventRepository, tasks *TaskService) *HistoryService {
+	return &HistoryService{events: events, tasks: tasks}
+}
+
+// History pages through the audit trail of a task. It keeps working after
+// the task is trashed or purged; only a task that never existed is
+// ErrNotFound. With access control on, the history of a purged task is
+// gone along with its members.
+func (s *HistoryService) History(ctx context.Context, id, page, pageSize string) (*EventPage, error) {
+	if err := s.tasks.Authorize(ctx, id, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	pp, err := parsePage(page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.events.Count(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return nil, ErrNotFound
+	}
+
+	events, err := s.events.List(ctx, id, pp.pagination())
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(events) > pp.Size
+	if hasMore {
+		events = events[:pp.Size]
+	}
+
+	return &EventPage{
+		Events:   events,
+		Total:    total,
+		Page:     pp.Page,
+		PageSize: pp.Size,
+		HasMore:  hasMore,
+	}, nil
+}
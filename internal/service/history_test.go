@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// fakeEventRepo stores events oldest first, as the trigger appends them.
+type fakeEventRepo struct {
+	events []models.TaskEvent
+}
+
+func (f *fakeEventRepo) add(taskID string, action models.EventAction) {
+	f.events = append(f.events, models.TaskEvent{ID: int64(len(f.events) + 1), TaskID: taskID, Action: action})
+}
+
+func (f *fakeEventRepo) List(_ context.Context, taskID string, p repository.Pagination) ([]models.TaskEvent, error) {
+	out := []models.TaskEvent{}
+	for i := len(f.events) - 1; i >= 0; i-- {
+		if f.events[i].TaskID == taskID {
+			out = append(out, f.events[i])
+		}
+	}
+	if p.Offset >= len(out) {
+		return []models.TaskEvent{}, nil
+	}
+	out = out[p.Offset:]
+	if p.Limit > 0 && len(out) > p.Limit {
+		out = out[:p.Limit]
+	}
+	return out, nil
+}
+
+func (f *fakeEventRepo) Count(ctx context.Context, taskID string) (int, error) {
+	all, _ := f.List(ctx, taskID, repository.Pagination{})
+	return len(all), nil
+}
+
+func TestHistory_PagesNewestFirst(t *testing.T) {
+	repo := &fakeEventRepo{}
+	repo.add("a", models.EventCreated)
+	repo.add("b", models.EventCreated)
+	for range 4 {
+		repo.add("a", models.EventUpdated)
+	}
+	repo.add("a", models.EventTrashed)
+	svc := NewHistoryService(repo, NewTaskService(newFakeTaskRepo()))
+
+	first, err := svc.History(context.Background(), "a", "", "3")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if first.Total != 6 || !first.HasMore || len(first.Events) != 3 {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first.Events[0].Action != models.EventTrashed {
+		t.Fatalf("expected newest event first, got %s", first.Events[0].Action)
+	}
+
+	last, err := svc.History(context.Background(), "a", "2", "3")
+	if err != nil {
+		t.Fatalf("History page 2: %v", err)
+	}
+	if last.HasMore || len(last.Events) != 3 || last.Events[2].Action != models.EventCreated {
+		t.Fatalf("unexpected last page: %+v", last)
+	}
+}
+
+func TestHistory_UnknownTask(t *testing.T) {
+	svc := NewHistoryService(&fakeEventRepo{}, NewTaskService(newFakeTaskRepo()))
+
+	if _, err := svc.History(context.Background(), "missing", "", ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
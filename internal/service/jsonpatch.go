@@ -0,0 +1,272 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch    = errors.New("patch is invalid")
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+)
+
+// mergePatch applies an RFC 7396 JSON Merge Patch to target.
+func mergePatch(target, patch any) any {
+	obj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = map[string]any{}
+	}
+	for k, v := range obj {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+		doc[k] = mergePatch(doc[k], v)
+	}
+	return doc
+}
+
+// patchOp is one RFC 6902 JSON Patch operation.
+type patchOp struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From string `json:"from"`
+	// Value is nil when the member is absent; an explicit null arrives as
+	// the raw literal, so it can clear a field
+	Value json.RawMessage `json:"value"`
+}
+
+func (op patchOp) value() (any, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%w: %s %q needs a value", ErrInvalidPatch, op.Op, op.Path)
+	}
+	var v any
+	if err := json.Unmarshal(op.Value, &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return v, nil
+}
+
+// jsonPatch applies RFC 6902 operations in order; the first failure
+// aborts the whole patch.
+func jsonPatch(doc any, ops []patchOp) (any, error) {
+	var err error
+	for _, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			var v any
+			if v, err = op.value(); err != nil {
+				return nil, err
+			}
+			switch op.Op {
+			case "add":
+				doc, err = pointerAdd(doc, op.Path, v)
+			case "replace":
+				if doc, _, err = pointerRemove(doc, op.Path); err == nil {
+					doc, err = pointerAdd(doc, op.Path, v)
+				}
+			case "test":
+				var got any
+				if got, err = pointerGet(doc, op.Path); err == nil && !reflect.DeepEqual(got, v) {
+					return nil, fmt.Errorf("%w: %q", ErrPatchTestFailed, op.Path)
+				}
+			}
+		case "remove":
+			doc, _, err = pointerRemove(doc, op.Path)
+		case "move":
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, fmt.Errorf("%w: cannot move %q into itself", ErrInvalidPatch, op.From)
+			}
+			var v any
+			if doc, v, err = pointerRemove(doc, op.From); err == nil {
+				doc, err = pointerAdd(doc, op.Path, v)
+			}
+		case "copy":
+			var v any
+			if v, err = pointerGet(doc, op.From); err == nil {
+				doc, err = pointerAdd(doc, op.Path, deepCopy(v))
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// splitPointer decodes an RFC 6901 JSON Pointer into its reference tokens.
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("%w: bad pointer %q", ErrInvalidPatch, ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, n int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	limit := n
+	if appending {
+		limit++
+	}
+	if err != nil || i < 0 || i >= limit || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: bad array index %q", ErrInvalidPatch, token)
+	}
+	return i, nil
+}
+
+func pointerGet(doc any, ptr string) (any, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, t := range tokens {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[t]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, ptr)
+			}
+			cur = v
+		case []any:
+			i, err := arrayIndex(t, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, ptr)
+		}
+	}
+	return cur, nil
+}
+
+// pointerAdd returns doc with v added at ptr; arrays are rebuilt since
+// inserting may grow them.
+func pointerAdd(doc any, ptr string, v any) (any, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	parentPtr := ptr[:strings.LastIndex(ptr, "/")]
+	parent, err := pointerGet(doc, parentPtr)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = v
+		return doc, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), true)
+		if err != nil {
+			return nil, err
+		}
+		grown := append(node[:i:i], append([]any{v}, node[i:]...)...)
+		return pointerSet(doc, parentPtr, grown)
+	}
+	return nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, parentPtr)
+}
+
+// pointerSet overwrites the existing value at ptr.
+func pointerSet(doc any, ptr string, v any) (any, error) {
+	if ptr == "" {
+		return v, nil
+	}
+	parentPtr := ptr[:strings.LastIndex(ptr, "/")]
+	parent, err := pointerGet(doc, parentPtr)
+	if err != nil {
+		return nil, err
+	}
+	tokens, _ := splitPointer(ptr)
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = v
+	case []any:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = v
+	}
+	return doc, nil
+}
+
+// pointerRemove returns doc without the value at ptr, and that value.
+func pointerRemove(doc any, ptr string) (any, any, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	parentPtr := ptr[:strings.LastIndex(ptr, "/")]
+	parent, err := pointerGet(doc, parentPtr)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		v, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, ptr)
+		}
+		delete(node, last)
+		return doc, v, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := node[i]
+		shrunk := append(node[:i:i], node[i+1:]...)
+		doc, err = pointerSet(doc, parentPtr, shrunk)
+		return doc, v, err
+	}
+	return nil, nil, fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, parentPtr)
+}
+
+func deepCopy(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = deepCopy(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = deepCopy(child)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var (
+	ErrForbidden       = errors.New("you don't have permission to do that")
+	ErrInvalidRole     = errors.New("role must be owner, editor or viewer")
+	ErrLastOwner       = errors.New("a task must keep at least one owner")
+	ErrMemberNotFound  = errors.New("member not found")
+	errMembersDisabled = errors.New("access control is not configured")
+)
+
+// WithAccessControl makes every task operation check the caller's role:
+// viewers may read a task and its subtasks, editors may also change them,
+// and owners may also manage members and purge. Without it all callers,
+// including anonymous ones, may do anything.
+func WithAccessControl(members repository.TaskMemberRepository) Option {
+	return func(s *TaskService) {
+		s.members = members
+	}
+}
+
+type MemberInput struct {
+	Email string      `json:"email"`
+	Role  models.Role `json:"role"`
+}
+
+// caller is the authenticated user's id when access control is on; empty
+// when it is off.
+func (s *TaskService) caller(ctx context.Context) (string, error) {
+	if s.members == nil {
+		return "", nil
+	}
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return "", ErrUnauthenticated
+	}
+	return p.UserID, nil
+}
+
+// Authorize checks that the caller holds at least need on the task.
+// Callers who may not see the task at all get ErrNotFound, so task ids
+// can't be probed.
+func (s *TaskService) Authorize(ctx context.Context, id string, need models.Role) error {
+	userID, err := s.caller(ctx)
+	if err != nil || userID == "" {
+		return err
+	}
+
+	role, err := s.repo.Role(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrNotFound
+	}
+	if !role.Includes(need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizedTask is Authorize followed by fetching the task.
+func (s *TaskService) authorizedTask(ctx context.Context, id string, need models.Role) (*models.Task, error) {
+	if err := s.Authorize(ctx, id, need); err != nil {
+		return nil, err
+	}
+	return s.getTask(ctx, id)
+}
+
+// authorizeParent checks the caller may put subtasks under parentID.
+// Parents they can't see are reported like missing ones.
+func (s *TaskService) authorizeParent(ctx context.Context, parentID string) error {
+	err := s.Authorize(ctx, parentID, models.RoleEditor)
+	if errors.Is(err, ErrNotFound) {
+		return ErrInvalidParent
+	}
+	return err
+}
+
+func (s *TaskService) ListMembers(ctx context.Context, taskID string) ([]models.TaskMember, error) {
+	if s.members == nil {
+		return nil, errMembersDisabled
+	}
+	if err := s.Authorize(ctx, taskID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.members.List(ctx, taskID)
+}
+
+// SetMember grants a user a role on the task, or changes the role they
+// have. Only owners may do this.
+func (s *TaskService) SetMember(ctx context.Context, taskID string, in MemberInput) (*models.TaskMember, error) {
+	if s.members == nil {
+		return nil, errMembersDisabled
+	}
+	if err := s.Authorize(ctx, taskID, models.RoleOwner); err != nil {
+		return nil, err
+	}
+	if !in.Role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	email, err := normalizeEmail(in.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Role != models.RoleOwner {
+		members, err := s.members.List(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if lastOwner(members, func(m models.TaskMember) bool { return m.Email == email }) {
+			return nil, ErrLastOwner
+		}
+	}
+
+	m, err := s.members.Set(ctx, taskID, email, in.Role)
+	switch {
+	case errors.Is(err, models.ErrUserNotFound):
+		return nil, ErrUserNotFound
+	case errors.Is(err, models.ErrNotFound):
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+// RemoveMember takes a user's role on the task away. Owners may remove
+// anyone, and every member may remove themselves. Roles inherited from a
+// parent task are unaffected.
+func (s *TaskService) RemoveMember(ctx context.Context, taskID, userID string) error {
+	if s.members == nil {
+		return errMembersDisabled
+	}
+	need := models.RoleOwner
+	if p := auth.FromContext(ctx); p != nil && p.UserID == userID {
+		need = models.RoleViewer
+	}
+	if err := s.Authorize(ctx, taskID, need); err != nil {
+		return err
+	}
+
+	members, err := s.members.List(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if lastOwner(members, func(m models.TaskMember) bool { return m.UserID == userID }) {
+		return ErrLastOwner
+	}
+
+	if err := s.members.Remove(ctx, taskID, userID); err != nil {
+		if errors.Is(err, models.ErrMemberNotFound) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// lastOwner reports whether the member picked by is is the task's only
+// direct owner.
+func lastOwner(members []models.TaskMember, is func(models.TaskMember) bool) bool {
+	owners, target := 0, false
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+			target = target || is(m)
+		}
+	}
+	return target && owners == 1
+}
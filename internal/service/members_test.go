@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// fakeMemberRepo keeps members in the fake task repo so Role sees them.
+type fakeMemberRepo struct {
+	tasks *fakeTaskRepo
+	// users maps emails to user ids
+	users map[string]string
+}
+
+func (f *fakeMemberRepo) List(ctx context.Context, taskID string) ([]models.TaskMember, error) {
+	var out []models.TaskMember
+	for email, id := range f.users {
+		if role, ok := f.tasks.members[taskID][id]; ok {
+			out = append(out, models.TaskMember{TaskID: taskID, UserID: id, Email: email, Role: role})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMemberRepo) Set(ctx context.Context, taskID, email string, role models.Role) (*models.TaskMember, error) {
+	id, ok := f.users[email]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	if _, ok := f.tasks.store[taskID]; !ok {
+		return nil, models.ErrNotFound
+	}
+	if f.tasks.members[taskID] == nil {
+		f.tasks.members[taskID] = map[string]models.Role{}
+	}
+	f.tasks.members[taskID][id] = role
+	return &models.TaskMember{TaskID: taskID, UserID: id, Email: email, Role: role}, nil
+}
+
+func (f *fakeMemberRepo) Remove(ctx context.Context, taskID, userID string) error {
+	if _, ok := f.tasks.members[taskID][userID]; !ok {
+		return models.ErrMemberNotFound
+	}
+	delete(f.tasks.members[taskID], userID)
+	return nil
+}
+
+func newAccessControlledService() *TaskService {
+	tasks := newFakeTaskRepo()
+	return NewTaskService(tasks, WithAccessControl(&fakeMemberRepo{
+		tasks: tasks,
+		users: map[string]string{"alice@example.com": "alice", "bob@example.com": "bob", "carol@example.com": "carol"},
+	}))
+}
+
+func TestAccessControl_RequiresAuthentication(t *testing.T) {
+	svc := newAccessControlledService()
+
+	if _, err := svc.CreateTask(context.Background(), CreateTaskInput{Title: "anon"}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+	if _, err := svc.ListTasks(context.Background(), ListOptions{}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestAccessControl_RolesAreEnforcedAndInherited(t *testing.T) {
+	svc := newAccessControlledService()
+	alice, bob, carol := sessionCtx("alice"), sessionCtx("bob"), sessionCtx("carol")
+
+	parent, err := svc.CreateTask(alice, CreateTaskInput{Title: "project"})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	child, err := svc.CreateTask(alice, CreateTaskInput{Title: "step", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("create child err: %v", err)
+	}
+	if _, err := svc.SetMember(alice, parent.ID, MemberInput{Email: "bob@example.com", Role: models.RoleViewer}); err != nil {
+		t.Fatalf("set member err: %v", err)
+	}
+
+	// strangers can't tell the task exists
+	if _, err := svc.GetTask(carol, child.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a stranger, got %v", err)
+	}
+	// the viewer role reaches the subtask, but only for reading
+	if _, err := svc.GetTask(bob, child.ID); err != nil {
+		t.Fatalf("viewer read err: %v", err)
+	}
+	title := "renamed"
+	if _, err := svc.UpdateTask(bob, child.ID, UpdateTaskInput{Title: &title}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a viewer, got %v", err)
+	}
+	if _, err := svc.CreateTask(bob, CreateTaskInput{Title: "sneaky", ParentID: &parent.ID}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden adding a subtask as viewer, got %v", err)
+	}
+	if _, err := svc.CreateTask(carol, CreateTaskInput{Title: "sneaky", ParentID: &parent.ID}); !errors.Is(err, ErrInvalidParent) {
+		t.Fatalf("expected ErrInvalidParent for an unseen parent, got %v", err)
+	}
+
+	if _, err := svc.SetMember(alice, parent.ID, MemberInput{Email: "bob@example.com", Role: models.RoleEditor}); err != nil {
+		t.Fatalf("promote err: %v", err)
+	}
+	if _, err := svc.UpdateTask(bob, child.ID, UpdateTaskInput{Title: &title}); err != nil {
+		t.Fatalf("editor update err: %v", err)
+	}
+	if _, err := svc.SetMember(bob, parent.ID, MemberInput{Email: "carol@example.com", Role: models.RoleViewer}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for an editor managing members, got %v", err)
+	}
+}
+
+func TestAccessControl_ListTasksOnlyShowsVisibleTasks(t *testing.T) {
+	svc := newAccessControlledService()
+	alice, bob := sessionCtx("alice"), sessionCtx("bob")
+
+	if _, err := svc.CreateTask(alice, CreateTaskInput{Title: "alice's"}); err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	if _, err := svc.CreateTask(bob, CreateTaskInput{Title: "bob's"}); err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	page, err := svc.ListTasks(alice, ListOptions{})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].Title != "alice's" {
+		t.Fatalf("expected only alice's task, got %+v", page.Tasks)
+	}
+}
+
+func TestAccessControl_KeepsLastOwner(t *testing.T) {
+	svc := newAccessControlledService()
+	alice, bob := sessionCtx("alice"), sessionCtx("bob")
+
+	task, err := svc.CreateTask(alice, CreateTaskInput{Title: "mine"})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	if _, err := svc.SetMember(alice, task.ID, MemberInput{Email: "alice@example.com", Role: models.RoleEditor}); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner demoting the only owner, got %v", err)
+	}
+	if err := svc.RemoveMember(alice, task.ID, "alice"); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("expected ErrLastOwner removing the only owner, got %v", err)
+	}
+
+	if _, err := svc.SetMember(alice, task.ID, MemberInput{Email: "bob@example.com", Role: models.RoleOwner}); err != nil {
+		t.Fatalf("add owner err: %v", err)
+	}
+	if err := svc.RemoveMember(bob, task.ID, "alice"); err != nil {
+		t.Fatalf("co-owner remove err: %v", err)
+	}
+	if _, err := svc.GetTask(alice, task.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after removal, got %v", err)
+	}
+}
+
+func TestAccessControl_EmptyTrashOnlyPurgesOwnedTasks(t *testing.T) {
+	svc := newAccessControlledService()
+	alice, bob := sessionCtx("alice"), sessionCtx("bob")
+
+	mine, _ := svc.CreateTask(alice, CreateTaskInput{Title: "mine"})
+	theirs, _ := svc.CreateTask(bob, CreateTaskInput{Title: "theirs"})
+	if _, err := svc.SetMember(bob, theirs.ID, MemberInput{Email: "alice@example.com", Role: models.RoleEditor}); err != nil {
+		t.Fatalf("set member err: %v", err)
+	}
+	for _, id := range []string{mine.ID, theirs.ID} {
+		if err := svc.DeleteTask(alice, id, DeleteOptions{}); err != nil {
+			t.Fatalf("delete err: %v", err)
+		}
+	}
+
+	n, err := svc.EmptyTrash(alice)
+	if err != nil {
+		t.Fatalf("empty trash err: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 purged task, got %d", n)
+	}
+	if _, err := svc.RestoreTask(bob, theirs.ID); err != nil {
+		t.Fatalf("restore err: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import "encoding/json"
+
+// Nullable tells apart a JSON field that was omitted (Set == false)
+// from one explicitly sent as null (Set == true, Value == nil).
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	n.Set = true
+	if string(b) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+	return nil
+}
+
+// Null builds an explicit null in code.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true}
+}
+
+// Some wraps v as a set, non-null field.
+func Some[T any](v T) Nullable[T] {
+	return Nullable[T]{Set: true, Value: &v}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// Media types accepted by PatchTask
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var ErrUnsupportedPatch = errors.New("patch must be " + MergePatchType + " or " + JSONPatchType)
+
+// writableFields are the task document members a replacement or patch may change.
+var writableFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"status":      true,
+	"priority":    true,
+	"due_at":      true,
+	"parent_id":   true,
+}
+
+// ReplaceTaskInput is a full task representation; omitted optional fields
+// reset to their defaults rather than keeping their current value.
+type ReplaceTaskInput struct {
+	Title       string
+	Description string
+	Status      string
+	Priority    int
+	DueAt       *string `json:"due_at"`
+	ParentID    *string `json:"parent_id"`
+
+	IgnoreBlockers bool `json:"ignore_blockers"`
+	Reopen         bool `json:"reopen"`
+
+	IfMatch VersionMatch `json:"-"`
+}
+
+type PatchInput struct {
+	// ContentType selects the patch format, MergePatchType or JSONPatchType
+	ContentType string
+	Body        []byte
+
+	IgnoreBlockers bool
+	Reopen         bool
+	IfMatch        VersionMatch
+}
+
+// ReplaceTask overwrites every writable field of the task.
+func (s *TaskService) ReplaceTask(ctx context.Context, id string, in ReplaceTaskInput) (models.Task, error) {
+	if in.Status == "" {
+		return models.Task{}, ErrInvalidStatus
+	}
+	priority := in.Priority
+	if priority == 0 {
+		priority = models.PriorityDefault
+	}
+
+	return s.UpdateTask(ctx, id, UpdateTaskInput{
+		Title:          &in.Title,
+		Description:    &in.Description,
+		Status:         &in.Status,
+		Priority:       &priority,
+		DueAt:          Nullable[string]{Set: true, Value: in.DueAt},
+		ParentID:       Nullable[string]{Set: true, Value: in.ParentID},
+		IgnoreBlockers: in.IgnoreBlockers,
+		Reopen:         in.Reopen,
+		IfMatch:        in.IfMatch,
+	})
+}
+
+// PatchTask applies a merge patch or JSON patch to the task's JSON
+// representation and stores the result as a replacement. Read-only
+// members may be tested but not changed.
+func (s *TaskService) PatchTask(ctx context.Context, id string, in PatchInput) (models.Task, error) {
+	if err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return models.Task{}, err
+	}
+	existing, err := s.getTask(ctx, id)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := checkVersion(existing, in.IfMatch); err != nil {
+		return models.Task{}, err
+	}
+
+	raw, err := json.Marshal(existing)
+	if err != nil {
+		return models.Task{}, err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return models.Task{}, err
+	}
+	original := deepCopy(doc).(map[string]any)
+
+	switch in.ContentType {
+	case MergePatchType:
+		var patch any
+		if err := json.Unmarshal(in.Body, &patch); err != nil {
+			return models.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		doc = mergePatch(doc, patch)
+	case JSONPatchType:
+		var ops []patchOp
+		if err := json.Unmarshal(in.Body, &ops); err != nil {
+			return models.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		if doc, err = jsonPatch(doc, ops); err != nil {
+			return models.Task{}, err
+		}
+	default:
+		return models.Task{}, ErrUnsupportedPatch
+	}
+
+	patched, ok := doc.(map[string]any)
+	if !ok {
+		return models.Task{}, fmt.Errorf("%w: result must be an object", ErrInvalidPatch)
+	}
+	writable := make(map[string]any)
+	for k, v := range patched {
+		if writableFields[k] {
+			writable[k] = v
+		} else if !reflect.DeepEqual(v, original[k]) {
+			return models.Task{}, fmt.Errorf("%w: %s is read-only", ErrInvalidPatch, k)
+		}
+	}
+	for k := range original {
+		if _, kept := patched[k]; !kept && !writableFields[k] {
+			return models.Task{}, fmt.Errorf("%w: %s is read-only", ErrInvalidPatch, k)
+		}
+	}
+
+	var replacement ReplaceTaskInput
+	raw, err = json.Marshal(writable)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if err := json.Unmarshal(raw, &replacement); err != nil {
+		return models.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	replacement.IgnoreBlockers = in.IgnoreBlockers
+	replacement.Reopen = in.Reopen
+	// Only store the patch on top of the version it was computed from
+	replacement.IfMatch = VersionMatch{existing.Version}
+
+	return s.ReplaceTask(ctx, id, replacement)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func TestReplaceTask_ResetsOmittedFields(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	due := "2030-01-01T00:00:00Z"
+	task, err := svc.CreateTask(ctx, CreateTaskInput{Title: "full", Description: "old", Priority: 4, DueAt: &due})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if _, err := svc.ReplaceTask(ctx, task.ID, ReplaceTaskInput{Title: "replaced"}); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected a replacement without status to be rejected, got %v", err)
+	}
+
+	got, err := svc.ReplaceTask(ctx, task.ID, ReplaceTaskInput{Title: "replaced", Status: "todo"})
+	if err != nil {
+		t.Fatalf("ReplaceTask: %v", err)
+	}
+	if got.Description != "" || got.DueAt != nil || got.Priority != models.PriorityDefault {
+		t.Fatalf("expected omitted fields to reset, got %+v", got)
+	}
+}
+
+func TestPatchTask_MergePatch(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	due := "2030-01-01T00:00:00Z"
+	task, err := svc.CreateTask(ctx, CreateTaskInput{Title: "merge", Description: "keep me", DueAt: &due})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	got, err := svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: MergePatchType,
+		Body:        []byte(`{"title": "merged", "due_at": null}`),
+	})
+	if err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if got.Title != "merged" || got.DueAt != nil || got.Description != "keep me" || got.Status != models.StatusTodo {
+		t.Fatalf("unexpected result %+v", got)
+	}
+
+	if _, err := svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: MergePatchType,
+		Body:        []byte(`{"created_at": "2000-01-01T00:00:00Z"}`),
+	}); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected read-only field to be rejected, got %v", err)
+	}
+	if _, err := svc.PatchTask(ctx, task.ID, PatchInput{ContentType: "application/json", Body: []byte(`{}`)}); !errors.Is(err, ErrUnsupportedPatch) {
+		t.Fatalf("expected unsupported media type, got %v", err)
+	}
+}
+
+func TestPatchTask_JSONPatch(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	task := createChild(t, svc, "json patch", nil)
+
+	_, err := svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: JSONPatchType,
+		Body:        []byte(`[{"op": "test", "path": "/version", "value": 9}, {"op": "replace", "path": "/title", "value": "nope"}]`),
+	})
+	if !errors.Is(err, ErrPatchTestFailed) {
+		t.Fatalf("expected failed test to abort the patch, got %v", err)
+	}
+
+	got, err := svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: JSONPatchType,
+		Body: []byte(`[
+			{"op": "test", "path": "/version", "value": 1},
+			{"op": "copy", "from": "/title", "path": "/description"},
+			{"op": "replace", "path": "/title", "value": "patched"},
+			{"op": "replace", "path": "/priority", "value": 3}
+		]`),
+	})
+	if err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if got.Title != "patched" || got.Description != "json patch" || got.Priority != 3 || got.Version != 2 {
+		t.Fatalf("unexpected result %+v", got)
+	}
+}
+
+func TestPatchTask_JSONPatchNullClearsField(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	due := "2030-01-01T00:00:00Z"
+	task, err := svc.CreateTask(ctx, CreateTaskInput{Title: "due", DueAt: &due})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	_, err = svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: JSONPatchType,
+		Body:        []byte(`[{"op": "replace", "path": "/due_at"}]`),
+	})
+	if !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected a missing value to be rejected, got %v", err)
+	}
+
+	got, err := svc.PatchTask(ctx, task.ID, PatchInput{
+		ContentType: JSONPatchType,
+		Body:        []byte(`[{"op": "replace", "path": "/due_at", "value": null}]`),
+	})
+	if err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if got.DueAt != nil {
+		t.Fatalf("expected due_at to be cleared, got %v", got.DueAt)
+	}
+}
+
+func TestJSONPatch_Arrays(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"a": [1, 2, 3], "b": {"c~d/e": 1}}`), &doc)
+
+	var ops []patchOp
+	_ = json.Unmarshal([]byte(`[
+		{"op": "add", "path": "/a/1", "value": 9},
+		{"op": "remove", "path": "/a/0"},
+		{"op": "add", "path": "/a/-", "value": 4},
+		{"op": "move", "from": "/b/c~0d~1e", "path": "/f"}
+	]`), &ops)
+
+	got, err := jsonPatch(doc, ops)
+	if err != nil {
+		t.Fatalf("jsonPatch: %v", err)
+	}
+	var want any
+	_ = json.Unmarshal([]byte(`{"a": [9, 2, 3, 4], "b": {}, "f": 1}`), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if _, err := jsonPatch(want, []patchOp{{Op: "remove", Path: "/a/7"}}); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected out-of-range index to be rejected, got %v", err)
+	}
+}
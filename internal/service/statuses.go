@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var (
+	ErrInvalidStatusName     = errors.New("status name must be lower_snake_case and <= 30 characters")
+	ErrInvalidStatusCategory = errors.New("status category must be one of not_started, active or closed")
+	ErrInvalidStatusColor    = errors.New("status color must look like #1a2b3c")
+	ErrStatusNotFound        = errors.New("status not found")
+	ErrStatusExists          = errors.New("status already exists")
+	ErrStatusInUse           = errors.New("status is still used by tasks")
+)
+
+var (
+	statusNameRe  = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+	statusColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+const defaultStatusColor = "#9ca3af"
+
+// builtinStatuses mirrors the rows seeded by the status catalog migration.
+var builtinStatuses = []models.StatusDefinition{
+	{Name: models.StatusTodo, Category: models.CategoryNotStarted, Position: 0, Color: "#9ca3af"},
+	{Name: models.StatusInProgress, Category: models.CategoryActive, Position: 1, Color: "#3b82f6"},
+	{Name: models.StatusDone, Category: models.CategoryClosed, Position: 2, Color: "#22c55e"},
+}
+
+// statusCatalog is the read side of the catalog TaskService validates against.
+type statusCatalog interface {
+	GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error)
+	List(ctx context.Context) ([]models.StatusDefinition, error)
+}
+
+// builtinCatalog serves builtinStatuses when no repository is configured.
+type builtinCatalog struct{}
+
+func (builtinCatalog) GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error) {
+	for _, s := range builtinStatuses {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, models.ErrStatusNotFound
+}
+
+func (builtinCatalog) List(ctx context.Context) ([]models.StatusDefinition, error) {
+	return append([]models.StatusDefinition(nil), builtinStatuses...), nil
+}
+
+// WithStatusCatalog validates task statuses against the given repository
+// instead of the built-in todo/in_progress/done.
+func WithStatusCatalog(r repository.StatusRepository) Option {
+	return func(s *TaskService) {
+		s.statuses = r
+	}
+}
+
+type StatusInput struct {
+	Name     string
+	Category string
+	Position int
+	Color    string
+}
+
+// StatusService manages the status catalog. Every member of the workspace
+// may read it, and only its admins change it.
+type StatusService struct {
+	repo       repository.StatusRepository
+	workspaces *WorkspaceService
+}
+
+func NewStatusService(r repository.StatusRepository, workspaces *WorkspaceService) *StatusService {
+	return &StatusService{repo: r, workspaces: workspaces}
+}
+
+func validateStatusInput(in StatusInput) (*models.StatusDefinition, error) {
+	name := strings.TrimSpace(in.Name)
+	if !statusNameRe.MatchString(name) {
+		return nil, ErrInvalidStatusName
+	}
+	category := models.StatusCategory(in.Category)
+	if !category.Valid() {
+		return nil, ErrInvalidStatusCategory
+	}
+	color := in.Color
+	if color == "" {
+		color = defaultStatusColor
+	}
+	if !statusColorRe.MatchString(color) {
+		return nil, ErrInvalidStatusColor
+	}
+
+	return &models.StatusDefinition{
+		Name:     models.TaskStatus(name),
+		Category: category,
+		Position: in.Position,
+		Color:    strings.ToLower(color),
+	}, nil
+}
+
+func (s *StatusService) ListStatuses(ctx context.Context) ([]models.StatusDefinition, error) {
+	if err := s.workspaces.AuthorizeCurrent(ctx, models.WorkspaceMember); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx)
+}
+
+func (s *StatusService) CreateStatus(ctx context.Context, in StatusInput) (*models.StatusDefinition, error) {
+	if err := s.workspaces.AuthorizeCurrent(ctx, models.WorkspaceAdmin); err != nil {
+		return nil, err
+	}
+	def, err := validateStatusInput(in)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.repo.Create(ctx, def)
+	if err != nil {
+		return nil, mapStatusErr(err)
+	}
+	return out, nil
+}
+
+// UpdateStatus replaces a catalog entry; changing the name renames it on every task.
+func (s *StatusService) UpdateStatus(ctx context.Context, name string, in StatusInput) (*models.StatusDefinition, error) {
+	if err := s.workspaces.AuthorizeCurrent(ctx, models.WorkspaceAdmin); err != nil {
+		return nil, err
+	}
+	def, err := validateStatusInput(in)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.repo.Update(ctx, models.TaskStatus(name), def)
+	if err != nil {
+		return nil, mapStatusErr(err)
+	}
+	return out, nil
+}
+
+func (s *StatusService) DeleteStatus(ctx context.Context, name string) error {
+	if err := s.workspaces.AuthorizeCurrent(ctx, models.WorkspaceAdmin); err != nil {
+		return err
+	}
+	return mapStatusErr(s.repo.Delete(ctx, models.TaskStatus(name)))
+}
+
+func mapStatusErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrStatusNotFound):
+		return ErrStatusNotFound
+	case errors.Is(err, models.ErrStatusInUse):
+		return ErrStatusInUse
+	case errors.Is(err, models.ErrConflict):
+		return ErrStatusExists
+	}
+	return err
+}
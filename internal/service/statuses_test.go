@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeStatusRepo struct {
+	statuses map[models.TaskStatus]models.StatusDefinition
+}
+
+func newFakeStatusRepo() *fakeStatusRepo {
+	f := &fakeStatusRepo{statuses: make(map[models.TaskStatus]models.StatusDefinition)}
+	for _, s := range builtinStatuses {
+		f.statuses[s.Name] = s
+	}
+	return f
+}
+
+func (f *fakeStatusRepo) Create(ctx context.Context, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	if _, ok := f.statuses[s.Name]; ok {
+		return nil, models.ErrConflict
+	}
+	f.statuses[s.Name] = *s
+	return s, nil
+}
+
+func (f *fakeStatusRepo) GetByName(ctx context.Context, name models.TaskStatus) (*models.StatusDefinition, error) {
+	s, ok := f.statuses[name]
+	if !ok {
+		return nil, models.ErrStatusNotFound
+	}
+	return &s, nil
+}
+
+func (f *fakeStatusRepo) List(ctx context.Context) ([]models.StatusDefinition, error) {
+	out := make([]models.StatusDefinition, 0, len(f.statuses))
+	for _, s := range f.statuses {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Position != out[j].Position {
+			return out[i].Position < out[j].Position
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func (f *fakeStatusRepo) Update(ctx context.Context, name models.TaskStatus, s *models.StatusDefinition) (*models.StatusDefinition, error) {
+	if _, ok := f.statuses[name]; !ok {
+		return nil, models.ErrStatusNotFound
+	}
+	delete(f.statuses, name)
+	f.statuses[s.Name] = *s
+	return s, nil
+}
+
+func (f *fakeStatusRepo) Delete(ctx context.Context, name models.TaskStatus) error {
+	if _, ok := f.statuses[name]; !ok {
+		return models.ErrStatusNotFound
+	}
+	delete(f.statuses, name)
+	return nil
+}
+
+// newStatusService serves statuses in workspace w1, where alice is an admin
+// and bob a member.
+func newStatusService(statuses *fakeStatusRepo) *StatusService {
+	workspaces := newFakeWorkspaceRepo()
+	workspaces.Create(context.Background(), &models.Workspace{Slug: "acme", Name: "Acme"}, "alice")
+	workspaces.SetMember(context.Background(), "w1", "bob@example.com", models.WorkspaceMember)
+	return NewStatusService(statuses, NewWorkspaceService(workspaces))
+}
+
+func TestCreateStatus_Validation(t *testing.T) {
+	svc := newStatusService(newFakeStatusRepo())
+	ctx := sessionCtx("alice")
+
+	cases := []struct {
+		in   StatusInput
+		want error
+	}{
+		{StatusInput{Name: "In Review", Category: "active"}, ErrInvalidStatusName},
+		{StatusInput{Name: "review", Category: "waiting"}, ErrInvalidStatusCategory},
+		{StatusInput{Name: "review", Category: "active", Color: "blue"}, ErrInvalidStatusColor},
+		{StatusInput{Name: "done", Category: "closed"}, ErrStatusExists},
+	}
+	for _, c := range cases {
+		if _, err := svc.CreateStatus(ctx, c.in); !errors.Is(err, c.want) {
+			t.Errorf("CreateStatus(%+v): expected %v, got %v", c.in, c.want, err)
+		}
+	}
+
+	got, err := svc.CreateStatus(ctx, StatusInput{Name: "review", Category: "active", Color: "#ABCDEF"})
+	if err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+	if got.Color != "#abcdef" {
+		t.Fatalf("expected color to be lowercased, got %q", got.Color)
+	}
+}
+
+func TestUpdateTask_CustomStatusFollowsCategoryWorkflow(t *testing.T) {
+	statuses := newFakeStatusRepo()
+	if _, err := newStatusService(statuses).CreateStatus(sessionCtx("alice"), StatusInput{
+		Name: "review", Category: "active", Position: 2,
+	}); err != nil {
+		t.Fatalf("CreateStatus: %v", err)
+	}
+
+	svc := NewTaskService(newFakeTaskRepo(), WithStatusCatalog(statuses))
+	task := createChild(t, svc, "custom status", nil)
+
+	if _, err := setStatus(svc, task.ID, "review", false); err != nil {
+		t.Fatalf("todo -> review: %v", err)
+	}
+	if _, err := setStatus(svc, task.ID, models.StatusInProgress, false); err != nil {
+		t.Fatalf("review -> in_progress: %v", err)
+	}
+	if _, err := setStatus(svc, task.ID, "unknown", false); !errors.Is(err, ErrInvalidStatus) {
+		t.Fatalf("expected unknown status to be rejected, got %v", err)
+	}
+	done, err := setStatus(svc, task.ID, models.StatusDone, false)
+	if err != nil {
+		t.Fatalf("in_progress -> done: %v", err)
+	}
+	if done.CompletedAt == nil {
+		t.Fatalf("expected completed_at to be stamped")
+	}
+}
+
+func TestStatuses_OnlyAdminsChangeTheCatalog(t *testing.T) {
+	svc := newStatusService(newFakeStatusRepo())
+	bob, carol := sessionCtx("bob"), sessionCtx("carol")
+	review := StatusInput{Name: "review", Category: "active"}
+
+	if _, err := svc.ListStatuses(context.Background()); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+	if _, err := svc.ListStatuses(carol); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for an outsider, got %v", err)
+	}
+	if got, err := svc.ListStatuses(bob); err != nil || len(got) != len(builtinStatuses) {
+		t.Fatalf("expected a member to read the catalog, got %+v (err %v)", got, err)
+	}
+
+	if _, err := svc.CreateStatus(bob, review); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden creating as a member, got %v", err)
+	}
+	if _, err := svc.UpdateStatus(bob, "done", StatusInput{Name: "shipped", Category: "closed"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden renaming as a member, got %v", err)
+	}
+	if err := svc.DeleteStatus(bob, "done"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden deleting as a member, got %v", err)
+	}
+	if _, err := svc.CreateStatus(sessionCtx("alice"), review); err != nil {
+		t.Fatalf("expected an admin to create, got %v", err)
+	}
+}
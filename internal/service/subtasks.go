@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var (
+	ErrInvalidParent  = errors.New("parent task not found")
+	ErrParentCycle    = errors.New("a task cannot be nested under itself or one of its subtasks")
+	ErrTreeTooDeep    = errors.New("subtasks are nested too deeply")
+	ErrHasChildren    = errors.New("task has subtasks")
+	ErrInvalidCascade = errors.New("children must be one of cascade, orphan or reject")
+)
+
+// ChildPolicy decides what deleting a task does to its subtasks.
+type ChildPolicy string
+
+const (
+	ChildrenCascade ChildPolicy = "cascade" // delete the whole subtree
+	ChildrenOrphan  ChildPolicy = "orphan"  // keep children as top-level tasks
+	ChildrenReject  ChildPolicy = "reject"  // refuse while children exist
+)
+
+func (p ChildPolicy) valid() bool {
+	switch p {
+	case ChildrenCascade, ChildrenOrphan, ChildrenReject:
+		return true
+	}
+	return false
+}
+
+// WithChildPolicy sets the default used when DeleteTask isn't told otherwise.
+func WithChildPolicy(p ChildPolicy) Option {
+	return func(s *TaskService) {
+		if p.valid() {
+			s.childPolicy = p
+		}
+	}
+}
+
+type DeleteOptions struct {
+	// Children overrides the service default when set
+	Children ChildPolicy
+	// IfMatch refuses the delete unless the task is at one of these versions
+	IfMatch VersionMatch
+}
+
+// TaskNode is a task with its subtasks nested below it.
+type TaskNode struct {
+	models.Task
+	Children []*TaskNode `json:"children"`
+}
+
+// checkParent walks up from parentID and fails if it reaches taskID
+// (which would close a loop), or if taskID's subtree would end up nested
+// deeper than MaxTreeDepth under it. taskID is empty for a new task.
+func (s *TaskService) checkParent(ctx context.Context, taskID, parentID string) error {
+	height, err := s.subtreeHeight(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	cur := parentID
+	for depth := 1; ; depth++ {
+		if cur == taskID {
+			return ErrParentCycle
+		}
+		if depth+height >= repository.MaxTreeDepth {
+			return ErrTreeTooDeep
+		}
+
+		t, err := s.repo.GetByID(ctx, cur)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return ErrInvalidParent
+			}
+			return err
+		}
+		if t.ParentID == nil {
+			return nil
+		}
+		cur = *t.ParentID
+	}
+}
+
+// subtreeHeight counts the levels of subtasks below id; 0 for a leaf.
+func (s *TaskService) subtreeHeight(ctx context.Context, id string) (int, error) {
+	if id == "" {
+		return 0, nil
+	}
+	descendants, err := s.repo.Subtree(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// Subtree lists parents before children, so each parent's level is known
+	levels := map[string]int{id: 0}
+	height := 0
+	for _, t := range descendants {
+		if t.ParentID == nil {
+			continue
+		}
+		level := levels[*t.ParentID] + 1
+		levels[t.ID] = level
+		height = max(height, level)
+	}
+	return height, nil
+}
+
+// ListChildren pages through the direct subtasks of id.
+func (s *TaskService) ListChildren(ctx context.Context, id string, in ListOptions) (*TaskPage, error) {
+	if _, err := s.GetTask(ctx, id); err != nil {
+		return nil, err
+	}
+
+	in.ParentID = id
+	return s.ListTasks(ctx, in)
+}
+
+// GetSubtree returns id with every descendant nested under its parent.
+func (s *TaskService) GetSubtree(ctx context.Context, id string) (*TaskNode, error) {
+	root, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants, err := s.repo.Subtree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*TaskNode, len(descendants)+1)
+	top := &TaskNode{Task: *root, Children: []*TaskNode{}}
+	nodes[root.ID] = top
+	for _, t := range descendants {
+		nodes[t.ID] = &TaskNode{Task: t, Children: []*TaskNode{}}
+	}
+	// Subtree lists parents before children, so each parent already exists
+	for _, t := range descendants {
+		if t.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*t.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[t.ID])
+		}
+	}
+
+	return top, nil
+}
+
+func (s *TaskService) hasChildren(ctx context.Context, id string) (bool, error) {
+	n, err := s.repo.Count(ctx, repository.ListFilter{ParentID: &id, Archived: repository.ArchivedInclude})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func createChild(t *testing.T, svc *TaskService, title string, parent *models.Task) *models.Task {
+	t.Helper()
+
+	in := CreateTaskInput{Title: title}
+	if parent != nil {
+		in.ParentID = &parent.ID
+	}
+	task, err := svc.CreateTask(context.Background(), in)
+	if err != nil {
+		t.Fatalf("create %q err: %v", title, err)
+	}
+	return task
+}
+
+func TestUpdateTask_RejectsParentCycle(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+
+	root := createChild(t, svc, "root", nil)
+	child := createChild(t, svc, "child", root)
+	grandchild := createChild(t, svc, "grandchild", child)
+
+	_, err := svc.UpdateTask(context.Background(), root.ID, UpdateTaskInput{
+		ParentID: Some(grandchild.ID),
+	})
+	if !errors.Is(err, ErrParentCycle) {
+		t.Fatalf("expected ErrParentCycle, got %v", err)
+	}
+
+	_, err = svc.UpdateTask(context.Background(), root.ID, UpdateTaskInput{
+		ParentID: Some(root.ID),
+	})
+	if !errors.Is(err, ErrParentCycle) {
+		t.Fatalf("expected ErrParentCycle for self-parent, got %v", err)
+	}
+}
+
+func TestUpdateTask_RejectsMoveThatNestsSubtreeTooDeep(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+
+	chain := func(n int) (top, bottom *models.Task) {
+		top = createChild(t, svc, "top", nil)
+		bottom = top
+		for i := 1; i < n; i++ {
+			bottom = createChild(t, svc, "level", bottom)
+		}
+		return top, bottom
+	}
+	_, deep := chain(repository.MaxTreeDepth - 10)
+	tall, _ := chain(11)
+	short, _ := chain(10)
+
+	_, err := svc.UpdateTask(context.Background(), tall.ID, UpdateTaskInput{ParentID: Some(deep.ID)})
+	if !errors.Is(err, ErrTreeTooDeep) {
+		t.Fatalf("expected ErrTreeTooDeep, got %v", err)
+	}
+	if _, err := svc.UpdateTask(context.Background(), short.ID, UpdateTaskInput{ParentID: Some(deep.ID)}); err != nil {
+		t.Fatalf("move that fits err: %v", err)
+	}
+}
+
+func TestCreateTask_RejectsUnknownParent(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+
+	missing := "does-not-exist"
+	_, err := svc.CreateTask(context.Background(), CreateTaskInput{Title: "orphan", ParentID: &missing})
+	if !errors.Is(err, ErrInvalidParent) {
+		t.Fatalf("expected ErrInvalidParent, got %v", err)
+	}
+}
+
+func TestGetSubtree_NestsDescendants(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+
+	root := createChild(t, svc, "root", nil)
+	a := createChild(t, svc, "a", root)
+	createChild(t, svc, "b", root)
+	createChild(t, svc, "a1", a)
+
+	tree, err := svc.GetSubtree(context.Background(), root.ID)
+	if err != nil {
+		t.Fatalf("subtree err: %v", err)
+	}
+	if len(tree.Children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(tree.Children))
+	}
+	for _, c := range tree.Children {
+		if c.ID == a.ID && len(c.Children) != 1 {
+			t.Fatalf("expected a to have 1 child, got %d", len(c.Children))
+		}
+	}
+}
+
+func TestDeleteTask_ChildPolicies(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	root := createChild(t, svc, "root", nil)
+	child := createChild(t, svc, "child", root)
+
+	if err := svc.DeleteTask(context.Background(), root.ID, DeleteOptions{}); !errors.Is(err, ErrHasChildren) {
+		t.Fatalf("expected ErrHasChildren by default, got %v", err)
+	}
+
+	if err := svc.DeleteTask(context.Background(), root.ID, DeleteOptions{Children: ChildrenOrphan}); err != nil {
+		t.Fatalf("orphan delete err: %v", err)
+	}
+	orphan, err := svc.GetTask(context.Background(), child.ID)
+	if err != nil || orphan.ParentID != nil {
+		t.Fatalf("expected child to survive as top-level task, got %+v, %v", orphan, err)
+	}
+
+	parent := createChild(t, svc, "parent", nil)
+	kid := createChild(t, svc, "kid", parent)
+	if err := svc.DeleteTask(context.Background(), parent.ID, DeleteOptions{Children: ChildrenCascade}); err != nil {
+		t.Fatalf("cascade delete err: %v", err)
+	}
+	if _, err := svc.GetTask(context.Background(), kid.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected cascaded child to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var (
+	ErrInvalidTagName = errors.New("tag name is required, must be <= 50 characters and cannot contain commas")
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagExists      = errors.New("tag already exists")
+)
+
+type TagInput struct {
+	Name string
+}
+
+type TagService struct {
+	tags  repository.TagRepository
+	tasks *TaskService
+}
+
+func NewTagService(tags repository.TagRepository, tasks *TaskService) *TagService {
+	return &TagService{
+		tags:  tags,
+		tasks: tasks,
+	}
+}
+
+// normalizeTagName lower-cases and trims a tag so "Bug" and " bug" are the same tag.
+// Commas are reserved as the separator in tag list filters.
+func normalizeTagName(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n == "" || len(n) > 50 || strings.Contains(n, ",") {
+		return "", ErrInvalidTagName
+	}
+	return n, nil
+}
+
+func normalizeTagNames(names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		n, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func (s *TagService) CreateTag(ctx context.Context, in TagInput) (*models.Tag, error) {
+	name, err := normalizeTagName(in.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tags.Create(ctx, &models.Tag{Name: name})
+	if err != nil {
+		return nil, mapTagErr(err)
+	}
+	return tag, nil
+}
+
+func (s *TagService) ListTags(ctx context.Context) ([]models.Tag, error) {
+	return s.tags.List(ctx)
+}
+
+func (s *TagService) RenameTag(ctx context.Context, id string, in TagInput) (*models.Tag, error) {
+	name, err := normalizeTagName(in.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tags.Rename(ctx, id, name)
+	if err != nil {
+		return nil, mapTagErr(err)
+	}
+	return tag, nil
+}
+
+func (s *TagService) DeleteTag(ctx context.Context, id string) error {
+	return mapTagErr(s.tags.Delete(ctx, id))
+}
+
+// AttachTag links a tag to a task and returns the task with its updated tags.
+func (s *TagService) AttachTag(ctx context.Context, taskID, tagID string) (*models.Task, error) {
+	if _, err := s.tags.GetByID(ctx, tagID); err != nil {
+		return nil, mapTagErr(err)
+	}
+	if _, err := s.taskWithTags(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	if err := s.tags.Attach(ctx, taskID, tagID); err != nil {
+		return nil, mapTagErr(err)
+	}
+	return s.tasks.getTask(ctx, taskID)
+}
+
+func (s *TagService) DetachTag(ctx context.Context, taskID, tagID string) (*models.Task, error) {
+	if _, err := s.taskWithTags(ctx, taskID, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	if err := s.tags.Detach(ctx, taskID, tagID); err != nil {
+		return nil, mapTagErr(err)
+	}
+	return s.tasks.getTask(ctx, taskID)
+}
+
+// taskWithTags checks the caller holds need on the task and returns it.
+func (s *TagService) taskWithTags(ctx context.Context, taskID string, need models.Role) (*models.Task, error) {
+	return s.tasks.authorizedTask(ctx, taskID, need)
+}
+
+func mapTagErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrTagNotFound):
+		return ErrTagNotFound
+	case errors.Is(err, models.ErrNotFound):
+		return ErrNotFound
+	case errors.Is(err, models.ErrConflict):
+		return ErrTagExists
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/google/uuid"
+)
+
+type fakeTagRepo struct {
+	tags  map[string]models.Tag
+	links map[[2]string]bool
+}
+
+func newFakeTagRepo() *fakeTagRepo {
+	return &fakeTagRepo{
+		tags:  make(map[string]models.Tag),
+		links: make(map[[2]string]bool),
+	}
+}
+
+func (f *fakeTagRepo) Create(ctx context.Context, t *models.Tag) (*models.Tag, error) {
+	for _, existing := range f.tags {
+		if existing.Name == t.Name {
+			return nil, models.ErrConflict
+		}
+	}
+	copy := *t
+	copy.ID = uuid.NewString()
+	f.tags[copy.ID] = copy
+	return &copy, nil
+}
+
+func (f *fakeTagRepo) GetByID(ctx context.Context, id string) (*models.Tag, error) {
+	t, ok := f.tags[id]
+	if !ok {
+		return nil, models.ErrTagNotFound
+	}
+	return &t, nil
+}
+
+func (f *fakeTagRepo) List(ctx context.Context) ([]models.Tag, error) {
+	out := make([]models.Tag, 0, len(f.tags))
+	for _, t := range f.tags {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (f *fakeTagRepo) Rename(ctx context.Context, id, name string) (*models.Tag, error) {
+	t, ok := f.tags[id]
+	if !ok {
+		return nil, models.ErrTagNotFound
+	}
+	t.Name = name
+	f.tags[id] = t
+	return &t, nil
+}
+
+func (f *fakeTagRepo) Delete(ctx context.Context, id string) error {
+	if _, ok := f.tags[id]; !ok {
+		return models.ErrTagNotFound
+	}
+	delete(f.tags, id)
+	return nil
+}
+
+func (f *fakeTagRepo) Attach(ctx context.Context, taskID, tagID string) error {
+	f.links[[2]string{taskID, tagID}] = true
+	return nil
+}
+
+func (f *fakeTagRepo) Detach(ctx context.Context, taskID, tagID string) error {
+	key := [2]string{taskID, tagID}
+	if !f.links[key] {
+		return models.ErrTagNotFound
+	}
+	delete(f.links, key)
+	return nil
+}
+
+// --- TESTS ---
+
+func TestCreateTag_NormalisesAndRejectsDuplicates(t *testing.T) {
+	svc := NewTagService(newFakeTagRepo(), NewTaskService(newFakeTaskRepo()))
+
+	tag, err := svc.CreateTag(context.Background(), TagInput{Name: "  Bug "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tag.Name != "bug" {
+		t.Fatalf("expected normalised name 'bug', got %q", tag.Name)
+	}
+
+	_, err = svc.CreateTag(context.Background(), TagInput{Name: "BUG"})
+	if !errors.Is(err, ErrTagExists) {
+		t.Fatalf("expected ErrTagExists, got %v", err)
+	}
+}
+
+func TestCreateTag_RejectsInvalidNames(t *testing.T) {
+	svc := NewTagService(newFakeTagRepo(), NewTaskService(newFakeTaskRepo()))
+
+	for _, name := range []string{"", "   ", "a,b"} {
+		if _, err := svc.CreateTag(context.Background(), TagInput{Name: name}); !errors.Is(err, ErrInvalidTagName) {
+			t.Fatalf("expected ErrInvalidTagName for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestAttachTag_UnknownTaskOrTag(t *testing.T) {
+	tasks := newFakeTaskRepo()
+	svc := NewTagService(newFakeTagRepo(), NewTaskService(tasks))
+
+	if _, err := svc.AttachTag(context.Background(), "missing-task", "missing-tag"); !errors.Is(err, ErrTagNotFound) {
+		t.Fatalf("expected ErrTagNotFound, got %v", err)
+	}
+
+	tag, err := svc.CreateTag(context.Background(), TagInput{Name: "backend"})
+	if err != nil {
+		t.Fatalf("create tag err: %v", err)
+	}
+	if _, err := svc.AttachTag(context.Background(), "missing-task", tag.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
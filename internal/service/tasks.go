@@ -3,54 +3,130 @@ package service
 import (
 	"context"
 	"errors"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Luc1808/TaskAPI/internal/audit"
 	"github.com/Luc1808/TaskAPI/internal/repository"
 	"github.com/Luc1808/TaskAPI/pkg/models"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrInvalidTitle  = errors.New("title is required and must be <= 140 characters")
-	ErrInvalidStatus = errors.New("status is invalid")
-	ErrNotFound      = errors.New("task not found")
+	ErrInvalidTitle    = errors.New("title is required and must be <= 140 characters")
+	ErrInvalidStatus   = errors.New("status is invalid")
+	ErrInvalidPriority = errors.New("priority must be between 1 and 5")
+	ErrInvalidSort     = errors.New("sort is invalid")
+	ErrInvalidDueAt    = errors.New("due date must be an RFC 3339 timestamp between 2000 and 2100")
+	ErrInvalidFilter   = errors.New("filter is invalid")
+	ErrInvalidSearch   = errors.New("search must contain at least one letter or digit")
+	ErrInvalidPage     = errors.New("page is too large")
+	ErrNotFound        = errors.New("task not found")
 )
 
-var allowedStatus = map[string]bool{
-	"todo":        true,
-	"in_progress": true,
-	"done":        true,
-}
+// Accepted range for due dates; anything outside is almost certainly a typo
+var (
+	minDueAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxDueAt = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
 
 type CreateTaskInput struct {
 	Title       string
 	Description string
 	Status      string
+	Priority    int
+	DueAt       *string `json:"due_at"`
+	ParentID    *string `json:"parent_id"`
 }
 
 type UpdateTaskInput struct {
 	Title       *string
 	Description *string
 	Status      *string
+	Priority    *int
+	DueAt       Nullable[string] `json:"due_at"`
+	ParentID    Nullable[string] `json:"parent_id"`
+
+	// IgnoreBlockers allows closing a task while blockers are still open
+	IgnoreBlockers bool `json:"ignore_blockers"`
+	// Reopen allows the workflow's reopen transitions out of closed statuses
+	Reopen bool `json:"reopen"`
+
+	// IfMatch makes the update conditional on the task's current version
+	IfMatch VersionMatch `json:"-"`
 }
 
 type ListOptions struct {
-	Status   string
-	Search   string
-	Page     string
-	PageSize string
+	ParentID   string
+	BlockersOf string
+	Status     string
+	Priority   string
+	DueBefore  string
+	DueAfter   string
+	Overdue    string
+	Tags       []string
+	TagsAny    []string
+	TagsNone   []string
+	Search     string
+	Sort       []string
+	Cursor     string
+	Page       string
+	PageSize   string
+
+	// Archived is exclude (default), include or only; IncludeArchived=true
+	// is shorthand for include
+	Archived        string
+	IncludeArchived string
+
+	// Trashed lists the trash instead of live tasks
+	Trashed bool
+}
+
+// TaskPage is one page of ListTasks results. Cursors are empty when
+// there is nothing further in that direction.
+type TaskPage struct {
+	Tasks      []models.Task
+	NextCursor string
+	PrevCursor string
+
+	Total    int
+	Page     int // 0 when paging by cursor
+	PageSize int
+	HasMore  bool
 }
 
 type TaskService struct {
-	repo repository.TaskRepository
+	repo        repository.TaskRepository
+	cursors     cursorCodec
+	childPolicy ChildPolicy
+	workflow    Workflow
+	statuses    statusCatalog
+	members     repository.TaskMemberRepository
+}
+
+type Option func(*TaskService)
+
+// WithCursorSecret sets the HMAC key used to sign pagination cursors.
+func WithCursorSecret(secret []byte) Option {
+	return func(s *TaskService) {
+		s.cursors = newCursorCodec(secret)
+	}
 }
 
-func NewTaskService(r repository.TaskRepository) *TaskService {
-	return &TaskService{
-		repo: r,
+func NewTaskService(r repository.TaskRepository, opts ...Option) *TaskService {
+	s := &TaskService{
+		repo:        r,
+		cursors:     newCursorCodec(nil),
+		childPolicy: ChildrenReject,
+		workflow:    DefaultWorkflow(),
+		statuses:    builtinCatalog{},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func validateTitle(t string) error {
@@ -64,48 +140,163 @@ func validateTitle(t string) error {
 	return nil
 }
 
-func validateStatus(s string) error {
-	if s == "" {
-		return nil
+// resolveStatus looks a status up in the catalog.
+func (s *TaskService) resolveStatus(ctx context.Context, name string) (*models.StatusDefinition, error) {
+	def, err := s.statuses.GetByName(ctx, models.TaskStatus(name))
+	if err != nil {
+		if errors.Is(err, models.ErrStatusNotFound) {
+			return nil, ErrInvalidStatus
+		}
+		return nil, err
+	}
+	return def, nil
+}
+
+// defaultStatus is the first not-started status in catalog order.
+func (s *TaskService) defaultStatus(ctx context.Context) (*models.StatusDefinition, error) {
+	all, err := s.statuses.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range all {
+		if def.Category == models.CategoryNotStarted {
+			return &def, nil
+		}
 	}
-	if !allowedStatus[s] {
-		return ErrInvalidStatus
+	return nil, ErrInvalidStatus
+}
+
+func validatePriority(p int) error {
+	if p < models.PriorityMin || p > models.PriorityMax {
+		return ErrInvalidPriority
 	}
 	return nil
 }
 
+// parseDueAt parses an RFC 3339 timestamp and normalises it to UTC.
+func parseDueAt(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, ErrInvalidDueAt
+	}
+	t = t.UTC()
+	if t.Before(minDueAt) || !t.Before(maxDueAt) {
+		return time.Time{}, ErrInvalidDueAt
+	}
+	return t, nil
+}
+
+func parseOptionalDue(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := parseDueAt(s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseSort validates fields like "-due_at" against the sortable whitelist.
+// Relevance ("rank") only makes sense when searching.
+func parseSort(fields []string, searching bool) ([]repository.SortField, error) {
+	out := make([]repository.SortField, 0, len(fields))
+	seen := make(map[repository.SortKey]bool, len(fields))
+	for _, f := range fields {
+		desc := strings.HasPrefix(f, "-")
+		key := repository.SortKey(strings.TrimPrefix(f, "-"))
+		if !key.Valid() || seen[key] || (key == repository.SortRank && !searching) {
+			return nil, ErrInvalidSort
+		}
+		seen[key] = true
+		out = append(out, repository.SortField{Key: key, Desc: desc})
+	}
+	return out, nil
+}
+
 func (s *TaskService) CreateTask(ctx context.Context, in CreateTaskInput) (*models.Task, error) {
+	userID, err := s.caller(ctx)
+	if err != nil {
+		return &models.Task{}, err
+	}
 	if err := validateTitle(in.Title); err != nil {
 		return &models.Task{}, err
 	}
 
-	status := in.Status
-	if status == "" {
-		status = "todo"
+	var status *models.StatusDefinition
+	if in.Status == "" {
+		status, err = s.defaultStatus(ctx)
+	} else {
+		status, err = s.resolveStatus(ctx, in.Status)
 	}
-	if err := validateStatus(status); err != nil {
+	if err != nil {
 		return &models.Task{}, err
 	}
 
+	priority := in.Priority
+	if priority == 0 {
+		priority = models.PriorityDefault
+	}
+	if err := validatePriority(priority); err != nil {
+		return &models.Task{}, err
+	}
+
+	var dueAt *time.Time
+	if in.DueAt != nil {
+		d, err := parseDueAt(*in.DueAt)
+		if err != nil {
+			return &models.Task{}, err
+		}
+		dueAt = &d
+	}
+
+	id := uuid.NewString()
+	if in.ParentID != nil {
+		if err := s.authorizeParent(ctx, *in.ParentID); err != nil {
+			return &models.Task{}, err
+		}
+		if err := s.checkParent(ctx, "", *in.ParentID); err != nil {
+			return &models.Task{}, err
+		}
+	}
+
 	now := time.Now().UTC()
 
 	task := &models.Task{
-		ID:          uuid.NewString(),
+		ID:          id,
+		ParentID:    in.ParentID,
 		Title:       strings.TrimSpace(in.Title),
 		Description: in.Description,
-		Status:      models.TaskStatus(status),
+		Status:      status.Name,
+		Priority:    priority,
+		DueAt:       dueAt,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	// the creator becomes the task's owner
+	if userID != "" {
+		task.CreatedBy = &userID
+	} else if actor := audit.Actor(ctx); actor != "" {
+		task.CreatedBy = &actor
+	}
+	stampStatus(task, status.Category, now)
 
 	return s.repo.Create(ctx, task)
 }
 
 func (s *TaskService) GetTask(ctx context.Context, id string) (*models.Task, error) {
+	if err := s.Authorize(ctx, id, models.RoleViewer); err != nil {
+		return &models.Task{}, err
+	}
+	return s.getTask(ctx, id)
+}
+
+// getTask is GetTask for callers that have already authorized.
+func (s *TaskService) getTask(ctx context.Context, id string) (*models.Task, error) {
 	t, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
-			return &models.Task{}, err
+			return &models.Task{}, ErrNotFound
 		}
 
 		return &models.Task{}, err
@@ -113,28 +304,134 @@ func (s *TaskService) GetTask(ctx context.Context, id string) (*models.Task, err
 	return t, nil
 }
 
-func (s *TaskService) ListTasks(ctx context.Context, in ListOptions) ([]models.Task, error) {
-	page := parsePositiveInt(in.Page, 1)
-	size := parsePositiveInt(in.PageSize, 20)
-	offset := (page - 1) * size
+func (s *TaskService) ListTasks(ctx context.Context, in ListOptions) (*TaskPage, error) {
+	userID, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pp, err := parsePage(in.Page, in.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	page, size := pp.Page, pp.Size
 
 	var statusPtr *models.TaskStatus
 	if in.Status != "" {
-		if err := validateStatus(in.Status); err != nil {
+		if _, err := s.resolveStatus(ctx, in.Status); err != nil {
 			return nil, err
 		}
 		st := models.TaskStatus(in.Status)
 		statusPtr = &st
 	}
 
+	var priorityPtr *int
+	if in.Priority != "" {
+		p, err := strconv.Atoi(in.Priority)
+		if err != nil {
+			return nil, ErrInvalidPriority
+		}
+		if err := validatePriority(p); err != nil {
+			return nil, err
+		}
+		priorityPtr = &p
+	}
+
+	dueBefore, err := parseOptionalDue(in.DueBefore)
+	if err != nil {
+		return nil, err
+	}
+	dueAfter, err := parseOptionalDue(in.DueAfter)
+	if err != nil {
+		return nil, err
+	}
+	if dueBefore != nil && dueAfter != nil && !dueAfter.Before(*dueBefore) {
+		return nil, ErrInvalidFilter
+	}
+
+	overdue := false
+	if in.Overdue != "" {
+		overdue, err = strconv.ParseBool(in.Overdue)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+	}
+
+	archived, err := parseArchivedFilter(in.Archived, in.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTagNames(in.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tagsAny, err := normalizeTagNames(in.TagsAny)
+	if err != nil {
+		return nil, err
+	}
+	tagsNone, err := normalizeTagNames(in.TagsNone)
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.TrimSpace(in.Search)
+	if search != "" && repository.PrefixTSQuery(search) == "" {
+		return nil, ErrInvalidSearch
+	}
+
+	sorts, err := parseSort(in.Sort, search != "")
+	if err != nil {
+		return nil, err
+	}
+	if search != "" && len(sorts) == 0 {
+		sorts = []repository.SortField{{Key: repository.SortRank, Desc: true}}
+	}
+
+	var parentPtr *string
+	if in.ParentID != "" {
+		parentPtr = &in.ParentID
+	}
+
+	var blockersOfPtr *string
+	if in.BlockersOf != "" {
+		blockersOfPtr = &in.BlockersOf
+	}
+
 	repoFilter := repository.ListFilter{
-		Status: statusPtr,
-		Search: in.Search,
+		ParentID:   parentPtr,
+		BlockersOf: blockersOfPtr,
+		Status:     statusPtr,
+		Priority:   priorityPtr,
+		DueBefore:  dueBefore,
+		DueAfter:   dueAfter,
+		Overdue:    overdue,
+		Tags:       tags,
+		TagsAny:    tagsAny,
+		TagsNone:   tagsNone,
+		Search:     search,
+		Sort:       sorts,
+		Trashed:    in.Trashed,
+		Archived:   archived,
+		VisibleTo:  userID,
 	}
 
-	repoPagination := repository.Pagination{
-		Limit:  size,
-		Offset: offset,
+	repoPagination := pp.pagination()
+
+	if in.Cursor != "" {
+		// Cursors only encode (created_at, id), so they can't follow a custom sort
+		if len(sorts) > 0 {
+			return nil, ErrInvalidCursor
+		}
+		c, err := s.cursors.decode(in.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Dir == cursorNext {
+			repoPagination.After = c.toRepo()
+		} else {
+			repoPagination.Before = c.toRepo()
+		}
 	}
 
 	tasks, err := s.repo.List(ctx, repoFilter, repoPagination)
@@ -142,18 +439,72 @@ func (s *TaskService) ListTasks(ctx context.Context, in ListOptions) ([]models.T
 		return nil, err
 	}
 
-	return tasks, err
-	// return s.repo.List(ctx, filter, pagination)
+	hasMore := len(tasks) > size
+	if hasMore {
+		if repoPagination.Before != nil {
+			tasks = tasks[len(tasks)-size:]
+		} else {
+			tasks = tasks[:size]
+		}
+	}
+
+	total, err := s.repo.Count(ctx, repoFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TaskPage{
+		Tasks:    tasks,
+		Total:    total,
+		PageSize: size,
+		HasMore:  hasMore,
+	}
+	if in.Cursor == "" {
+		result.Page = page
+	}
+	if len(sorts) > 0 || len(tasks) == 0 {
+		return result, nil
+	}
+
+	first, last := tasks[0], tasks[len(tasks)-1]
+	switch {
+	case repoPagination.Before != nil:
+		// We came from the page after this one, so it always exists
+		result.NextCursor = s.cursors.encode(last, cursorNext)
+		if hasMore {
+			result.PrevCursor = s.cursors.encode(first, cursorPrev)
+		}
+	case repoPagination.After != nil:
+		result.PrevCursor = s.cursors.encode(first, cursorPrev)
+		if hasMore {
+			result.NextCursor = s.cursors.encode(last, cursorNext)
+		}
+	default:
+		if repoPagination.Offset > 0 {
+			result.PrevCursor = s.cursors.encode(first, cursorPrev)
+		}
+		if hasMore {
+			result.NextCursor = s.cursors.encode(last, cursorNext)
+		}
+	}
+
+	return result, nil
 }
 
 func (s *TaskService) UpdateTask(ctx context.Context, id string, in UpdateTaskInput) (models.Task, error) {
+	if err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return models.Task{}, err
+	}
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
-			return models.Task{}, err
+			return models.Task{}, ErrNotFound
 		}
 		return models.Task{}, err
 	}
+	if err := checkVersion(existing, in.IfMatch); err != nil {
+		return models.Task{}, err
+	}
 
 	if in.Title != nil {
 		if err := validateTitle(*in.Title); err != nil {
@@ -164,29 +515,136 @@ func (s *TaskService) UpdateTask(ctx context.Context, id string, in UpdateTaskIn
 	if in.Description != nil {
 		existing.Description = *in.Description
 	}
-	if in.Status != nil {
-		if err := validateStatus(*in.Status); err != nil {
+	var moved *models.StatusDefinition
+	if in.Status != nil && *in.Status != "" && *in.Status != string(existing.Status) {
+		current, err := s.resolveStatus(ctx, string(existing.Status))
+		if err != nil {
+			return models.Task{}, err
+		}
+		next, err := s.resolveStatus(ctx, *in.Status)
+		if err != nil {
 			return models.Task{}, err
 		}
-		if *in.Status != "" {
-			existing.Status = models.TaskStatus(*in.Status)
+		if err := s.workflow.Check(*current, *next, in.Reopen); err != nil {
+			return models.Task{}, err
+		}
+		if next.Category == models.CategoryClosed && current.Category != models.CategoryClosed &&
+			existing.Blocked && !in.IgnoreBlockers {
+			return models.Task{}, ErrBlocked
+		}
+		existing.Status = next.Name
+		moved = next
+	} else if in.Status != nil && *in.Status != "" {
+		if _, err := s.resolveStatus(ctx, *in.Status); err != nil {
+			return models.Task{}, err
 		}
 	}
+	if in.Priority != nil {
+		if err := validatePriority(*in.Priority); err != nil {
+			return models.Task{}, err
+		}
+		existing.Priority = *in.Priority
+	}
+	if in.DueAt.Set {
+		if in.DueAt.Value == nil {
+			existing.DueAt = nil
+		} else {
+			d, err := parseDueAt(*in.DueAt.Value)
+			if err != nil {
+				return models.Task{}, err
+			}
+			existing.DueAt = &d
+		}
+	}
+
+	if in.ParentID.Set {
+		if in.ParentID.Value != nil {
+			if existing.ParentID == nil || *existing.ParentID != *in.ParentID.Value {
+				if err := s.authorizeParent(ctx, *in.ParentID.Value); err != nil {
+					return models.Task{}, err
+				}
+			}
+			if err := s.checkParent(ctx, existing.ID, *in.ParentID.Value); err != nil {
+				return models.Task{}, err
+			}
+		}
+		existing.ParentID = in.ParentID.Value
+	}
 
 	existing.UpdatedAt = time.Now().UTC()
+	if moved != nil {
+		stampStatus(existing, moved.Category, existing.UpdatedAt)
+	}
 
+	// The repository only writes if the version is still the one read above
 	updated, err := s.repo.Update(ctx, existing)
 	if err != nil {
-		return models.Task{}, err
+		return models.Task{}, mapUpdateErr(err)
 	}
 
 	return *updated, nil
 }
 
-func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		if errors.Is(err, models.ErrNotFound) {
+// mapUpdateErr translates the errors of a conditional repository Update.
+func mapUpdateErr(err error) error {
+	switch {
+	case errors.Is(err, models.ErrVersionConflict):
+		return ErrPreconditionFailed
+	case errors.Is(err, models.ErrNotFound):
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *TaskService) DeleteTask(ctx context.Context, id string, opts DeleteOptions) error {
+	if err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return err
+	}
+	policy := s.childPolicy
+	if opts.Children != "" {
+		if !opts.Children.valid() {
+			return ErrInvalidCascade
+		}
+		policy = opts.Children
+	}
+
+	// The delete only goes ahead at the version that passed If-Match
+	version := 0
+	if opts.IfMatch != nil {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := checkVersion(existing, opts.IfMatch); err != nil {
+			return err
+		}
+		version = existing.Version
+	}
+
+	del := s.repo.Delete
+	switch policy {
+	case ChildrenCascade:
+		del = s.repo.DeleteTree
+	case ChildrenReject:
+		has, err := s.hasChildren(ctx, id)
+		if err != nil {
+			return err
+		}
+		if has {
+			return ErrHasChildren
+		}
+	}
+	// ChildrenOrphan relies on parent_id's ON DELETE SET NULL
+
+	if err := del(ctx, id, version); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
 			return ErrNotFound
+		case errors.Is(err, models.ErrVersionConflict):
+			return ErrPreconditionFailed
 		}
 		return err
 	}
@@ -194,6 +652,31 @@ func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
 	return nil
 }
 
+// pageParams is a page-number request with its size clamped to
+// 1..repository.MaxPageSize.
+type pageParams struct {
+	Page, Size int
+}
+
+// parsePage reads the page and page_size parameters. Missing or bad values
+// fall back to the first page and the default size; a page too far out for
+// its offset to fit an int is ErrInvalidPage.
+func parsePage(page, pageSize string) (pageParams, error) {
+	p := pageParams{
+		Page: parsePositiveInt(page, 1),
+		Size: min(parsePositiveInt(pageSize, repository.DefaultPageSize), repository.MaxPageSize),
+	}
+	if p.Page-1 > math.MaxInt/p.Size {
+		return pageParams{}, ErrInvalidPage
+	}
+	return p, nil
+}
+
+// pagination asks for one extra row to learn whether another page exists.
+func (p pageParams) pagination() repository.Pagination {
+	return repository.Pagination{Limit: p.Size + 1, Offset: (p.Page - 1) * p.Size}
+}
+
 func parsePositiveInt(s string, def int) int {
 	if s == "" {
 		return def
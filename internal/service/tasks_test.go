@@ -1,8 +1,14 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
 	"testing"
 	"time"
 
@@ -12,54 +18,284 @@ import (
 
 type fakeTaskRepo struct {
 	store map[string]models.Task
+	// blockers maps a task to the tasks blocking it
+	blockers map[string][]string
+	// members maps a task to the roles granted directly on it, by user
+	members map[string]map[string]models.Role
+	// statuses decides which statuses are closed, like ClosedStatuses does
+	// in SQL; the built-in catalog when nil
+	statuses statusCatalog
+}
+
+// closed reports whether status is in the closed category.
+func (f *fakeTaskRepo) closed(ctx context.Context, status models.TaskStatus) bool {
+	var catalog statusCatalog = builtinCatalog{}
+	if f.statuses != nil {
+		catalog = f.statuses
+	}
+	s, err := catalog.GetByName(ctx, status)
+	return err == nil && s.Category == models.CategoryClosed
 }
 
 func newFakeTaskRepo() *fakeTaskRepo {
 	return &fakeTaskRepo{
-		store: make(map[string]models.Task),
+		store:    make(map[string]models.Task),
+		blockers: make(map[string][]string),
+		members:  make(map[string]map[string]models.Role),
 	}
 }
 
 func (f *fakeTaskRepo) Create(ctx context.Context, t *models.Task) (*models.Task, error) {
 	copy := *t
+	copy.Version = 1
 	f.store[t.ID] = copy
+	if t.CreatedBy != nil {
+		f.members[t.ID] = map[string]models.Role{*t.CreatedBy: models.RoleOwner}
+	}
 	return &copy, nil
 }
 
 func (f *fakeTaskRepo) GetByID(ctx context.Context, id string) (*models.Task, error) {
 	t, ok := f.store[id]
-	if !ok {
+	if !ok || t.DeletedAt != nil {
 		return &models.Task{}, models.ErrNotFound
 	}
 	copy := t
+	for _, b := range f.blockers[id] {
+		if !f.closed(ctx, f.store[b].Status) {
+			copy.Blocked = true
+		}
+	}
 	return &copy, nil
 }
 
+// Role walks up the ancestors like the task_role SQL function.
+func (f *fakeTaskRepo) Role(ctx context.Context, id, userID string) (models.Role, error) {
+	var best models.Role
+	for depth := 0; depth < repository.MaxTreeDepth; depth++ {
+		t, ok := f.store[id]
+		if !ok {
+			break
+		}
+		if role := f.members[id][userID]; role.Includes(best) {
+			best = role
+		}
+		if t.ParentID == nil {
+			break
+		}
+		id = *t.ParentID
+	}
+	return best, nil
+}
+
 func (f *fakeTaskRepo) List(ctx context.Context, filter repository.ListFilter, pagination repository.Pagination) ([]models.Task, error) {
 	out := make([]models.Task, 0, len(f.store))
 	for _, v := range f.store {
+		if (v.DeletedAt != nil) != filter.Trashed {
+			continue
+		}
+		if filter.Archived == repository.ArchivedExclude && v.ArchivedAt != nil ||
+			filter.Archived == repository.ArchivedOnly && v.ArchivedAt == nil {
+			continue
+		}
+		if filter.ParentID != nil && (v.ParentID == nil || *v.ParentID != *filter.ParentID) {
+			continue
+		}
+		if filter.BlockersOf != nil && !slices.Contains(f.blockers[*filter.BlockersOf], v.ID) {
+			continue
+		}
+		if filter.VisibleTo != "" {
+			role, _ := f.Role(ctx, v.ID, filter.VisibleTo)
+			if !role.Includes(cmp.Or(filter.MinRole, models.RoleViewer)) {
+				continue
+			}
+		}
 		out = append(out, v)
 	}
+	sort.Slice(out, func(i, j int) bool { return keyLess(out[i], out[j]) })
+
+	if c := pagination.After; c != nil {
+		out = slices.DeleteFunc(out, func(t models.Task) bool {
+			return !keyLess(models.Task{CreatedAt: c.CreatedAt, ID: c.ID}, t)
+		})
+	}
+	if c := pagination.Before; c != nil {
+		out = slices.DeleteFunc(out, func(t models.Task) bool {
+			return !keyLess(t, models.Task{CreatedAt: c.CreatedAt, ID: c.ID})
+		})
+		if pagination.Limit > 0 && len(out) > pagination.Limit {
+			out = out[len(out)-pagination.Limit:]
+		}
+		return out, nil
+	}
+
+	if pagination.Offset >= len(out) {
+		return []models.Task{}, nil
+	}
+	out = out[pagination.Offset:]
+	if pagination.Limit > 0 && len(out) > pagination.Limit {
+		out = out[:pagination.Limit]
+	}
 	return out, nil
 }
 
+func (f *fakeTaskRepo) Count(ctx context.Context, filter repository.ListFilter) (int, error) {
+	out, _ := f.List(ctx, filter, repository.Pagination{})
+	return len(out), nil
+}
+
+func (f *fakeTaskRepo) Subtree(ctx context.Context, id string) ([]models.Task, error) {
+	var out []models.Task
+	queue := []string{id}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		children, _ := f.List(ctx, repository.ListFilter{ParentID: &parent}, repository.Pagination{})
+		for _, c := range children {
+			out = append(out, c)
+			queue = append(queue, c.ID)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeTaskRepo) DeleteTree(ctx context.Context, id string, version int) error {
+	descendants, _ := f.Subtree(ctx, id)
+	if err := f.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	now := f.store[id].DeletedAt
+	for _, d := range descendants {
+		d.DeletedAt = now
+		f.store[d.ID] = d
+	}
+	return nil
+}
+
+// trashedTree is id plus the descendants whose deleted_at matches keep.
+func (f *fakeTaskRepo) trashedTree(id string, keep func(models.Task) bool) []string {
+	out := []string{id}
+	for i := 0; i < len(out); i++ {
+		for _, v := range f.store {
+			if v.ParentID != nil && *v.ParentID == out[i] && keep(v) {
+				out = append(out, v.ID)
+			}
+		}
+	}
+	return out
+}
+
+func (f *fakeTaskRepo) Restore(ctx context.Context, id string) (*models.Task, error) {
+	root, ok := f.store[id]
+	if !ok || root.DeletedAt == nil {
+		return nil, models.ErrNotFound
+	}
+	when := *root.DeletedAt
+	for _, tid := range f.trashedTree(id, func(t models.Task) bool { return t.DeletedAt != nil && t.DeletedAt.Equal(when) }) {
+		t := f.store[tid]
+		t.DeletedAt = nil
+		t.Version++
+		f.store[tid] = t
+	}
+	if root := f.store[id]; root.ParentID != nil {
+		if parent, ok := f.store[*root.ParentID]; !ok || parent.DeletedAt != nil {
+			root.ParentID = nil
+			f.store[id] = root
+		}
+	}
+	return f.GetByID(ctx, id)
+}
+
+func (f *fakeTaskRepo) Purge(ctx context.Context, id string) error {
+	root, ok := f.store[id]
+	if !ok || root.DeletedAt == nil {
+		return models.ErrNotFound
+	}
+	for _, tid := range f.trashedTree(id, func(t models.Task) bool { return t.DeletedAt != nil }) {
+		delete(f.store, tid)
+	}
+	return nil
+}
+
+func (f *fakeTaskRepo) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, t := range f.store {
+		if t.DeletedAt != nil && t.DeletedAt.Before(before) {
+			delete(f.store, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeTaskRepo) ArchiveClosed(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	now := time.Now()
+	for id, t := range f.store {
+		if t.ArchivedAt == nil && t.DeletedAt == nil && f.closed(ctx, t.Status) &&
+			t.CompletedAt != nil && t.CompletedAt.Before(before) {
+			t.ArchivedAt = &now
+			t.Version++
+			f.store[id] = t
+			n++
+		}
+	}
+	return n, nil
+}
+
+// WithTx snapshots the store and restores it if fn fails.
+func (f *fakeTaskRepo) WithTx(ctx context.Context, fn func(tx repository.TaskRepository) error) error {
+	store := maps.Clone(f.store)
+	blockers := maps.Clone(f.blockers)
+	if err := fn(f); err != nil {
+		f.store = store
+		f.blockers = blockers
+		return err
+	}
+	return nil
+}
+
+func keyLess(a, b models.Task) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
 func (f *fakeTaskRepo) Update(ctx context.Context, t *models.Task) (*models.Task, error) {
-	_, ok := f.store[t.ID]
-	if !ok {
+	stored, ok := f.store[t.ID]
+	if !ok || stored.DeletedAt != nil {
 		return nil, models.ErrNotFound
 	}
+	if stored.Version != t.Version {
+		return nil, models.ErrVersionConflict
+	}
 
 	copy := *t
+	copy.Version++
 	f.store[t.ID] = copy
 
 	return &copy, nil
 }
 
-func (f *fakeTaskRepo) Delete(ctx context.Context, id string) error {
-	if _, ok := f.store[id]; !ok {
+func (f *fakeTaskRepo) Delete(ctx context.Context, id string, version int) error {
+	t, ok := f.store[id]
+	if !ok || t.DeletedAt != nil {
 		return models.ErrNotFound
 	}
-	delete(f.store, id)
+	if version != 0 && t.Version != version {
+		return models.ErrVersionConflict
+	}
+	now := time.Now()
+	t.DeletedAt = &now
+	f.store[id] = t
+	// Live children move to the top level
+	for k, v := range f.store {
+		if v.ParentID != nil && *v.ParentID == id && v.DeletedAt == nil {
+			v.ParentID = nil
+			f.store[k] = v
+		}
+	}
 	return nil
 }
 
@@ -141,3 +377,233 @@ func TestUpdateTask_RefreshesUpdatedAt(t *testing.T) {
 		t.Fatalf("expected title to be updated, got %q", updated.Title)
 	}
 }
+
+func TestCreateTask_DefaultPriority(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	task, err := svc.CreateTask(context.Background(), CreateTaskInput{
+		Title: "Triage inbox",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Priority != models.PriorityDefault {
+		t.Fatalf("expected default priority %d, got %d", models.PriorityDefault, task.Priority)
+	}
+}
+
+func TestUpdateTask_RejectsOutOfRangePriority(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	created, err := svc.CreateTask(context.Background(), CreateTaskInput{
+		Title:    "Rank me",
+		Priority: 3,
+	})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	bad := 6
+	_, err = svc.UpdateTask(context.Background(), created.ID, UpdateTaskInput{
+		Priority: &bad,
+	})
+	if !errors.Is(err, ErrInvalidPriority) {
+		t.Fatalf("expected ErrInvalidPriority, got %v", err)
+	}
+}
+
+func TestCreateTask_NormalisesDueAtToUTC(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	due := "2030-06-01T09:00:00+02:00"
+	task, err := svc.CreateTask(context.Background(), CreateTaskInput{
+		Title: "Ship release",
+		DueAt: &due,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2030, 6, 1, 7, 0, 0, 0, time.UTC)
+	if task.DueAt == nil || !task.DueAt.Equal(want) || task.DueAt.Location() != time.UTC {
+		t.Fatalf("expected due_at %v in UTC, got %v", want, task.DueAt)
+	}
+}
+
+func TestCreateTask_RejectsInvalidDueAt(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	for _, due := range []string{"tomorrow", "2030-06-01", "2030-06-01T09:00:00", "1970-01-01T00:00:00Z"} {
+		_, err := svc.CreateTask(context.Background(), CreateTaskInput{
+			Title: "Bad due",
+			DueAt: &due,
+		})
+		if !errors.Is(err, ErrInvalidDueAt) {
+			t.Fatalf("expected ErrInvalidDueAt for %q, got %v", due, err)
+		}
+	}
+}
+
+func TestUpdateTask_ClearsDueAtWithNull(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	due := "2030-06-01T09:00:00Z"
+	created, err := svc.CreateTask(context.Background(), CreateTaskInput{
+		Title: "Has a deadline",
+		DueAt: &due,
+	})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	var in UpdateTaskInput
+	if err := json.Unmarshal([]byte(`{"due_at": null}`), &in); err != nil {
+		t.Fatalf("decode err: %v", err)
+	}
+
+	updated, err := svc.UpdateTask(context.Background(), created.ID, in)
+	if err != nil {
+		t.Fatalf("update err: %v", err)
+	}
+	if updated.DueAt != nil {
+		t.Fatalf("expected due_at to be cleared, got %v", updated.DueAt)
+	}
+}
+
+func TestListTasks_CursorWalksForwardAndBack(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo, WithCursorSecret([]byte("test-secret")))
+
+	for i := range 5 {
+		if _, err := svc.CreateTask(context.Background(), CreateTaskInput{
+			Title: fmt.Sprintf("task %d", i),
+		}); err != nil {
+			t.Fatalf("create err: %v", err)
+		}
+	}
+
+	first, err := svc.ListTasks(context.Background(), ListOptions{PageSize: "2"})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(first.Tasks) != 2 || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("unexpected first page: %d tasks, next=%q prev=%q", len(first.Tasks), first.NextCursor, first.PrevCursor)
+	}
+
+	second, err := svc.ListTasks(context.Background(), ListOptions{PageSize: "2", Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(second.Tasks) != 2 || second.Tasks[0].ID == first.Tasks[1].ID {
+		t.Fatalf("expected a fresh second page, got %+v", second.Tasks)
+	}
+
+	back, err := svc.ListTasks(context.Background(), ListOptions{PageSize: "2", Cursor: second.PrevCursor})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(back.Tasks) != 2 || back.Tasks[0].ID != first.Tasks[0].ID || back.PrevCursor != "" {
+		t.Fatalf("expected to land on the first page again, got %+v", back.Tasks)
+	}
+}
+
+func TestListTasks_RejectsTamperedCursor(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo, WithCursorSecret([]byte("test-secret")))
+	other := NewTaskService(repo, WithCursorSecret([]byte("another-secret")))
+
+	token := other.cursors.encode(models.Task{ID: "abc", CreatedAt: time.Now()}, cursorNext)
+
+	_, err := svc.ListTasks(context.Background(), ListOptions{Cursor: token})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestListTasks_ReportsTotalsAndHasMore(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	for i := range 3 {
+		if _, err := svc.CreateTask(context.Background(), CreateTaskInput{
+			Title: fmt.Sprintf("task %d", i),
+		}); err != nil {
+			t.Fatalf("create err: %v", err)
+		}
+	}
+
+	page, err := svc.ListTasks(context.Background(), ListOptions{Page: "2", PageSize: "2"})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if page.Total != 3 || page.Page != 2 || page.PageSize != 2 || page.HasMore || len(page.Tasks) != 1 {
+		t.Fatalf("unexpected page meta: %+v", page)
+	}
+}
+
+func TestListTasks_BoundsPagination(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	createChild(t, svc, "only", nil)
+
+	page, err := svc.ListTasks(context.Background(), ListOptions{PageSize: "9223372036854775807"})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if page.PageSize != repository.MaxPageSize || len(page.Tasks) != 1 || page.HasMore {
+		t.Fatalf("expected page_size clamped to %d, got %+v", repository.MaxPageSize, page)
+	}
+
+	_, err = svc.ListTasks(context.Background(), ListOptions{Page: "9223372036854775807", PageSize: "100"})
+	if !errors.Is(err, ErrInvalidPage) {
+		t.Fatalf("expected ErrInvalidPage, got %v", err)
+	}
+	comments := NewCommentService(&fakeCommentRepo{}, svc, 0)
+	if _, err := comments.ListComments(context.Background(), page.Tasks[0].ID, "9223372036854775807", ""); !errors.Is(err, ErrInvalidPage) {
+		t.Fatalf("expected ErrInvalidPage for comments, got %v", err)
+	}
+}
+
+func TestListTasks_RejectsUnknownOrDuplicateSort(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	for _, sort := range [][]string{{"title; DROP TABLE tasks"}, {"priority", "-priority"}} {
+		_, err := svc.ListTasks(context.Background(), ListOptions{Sort: sort})
+		if !errors.Is(err, ErrInvalidSort) {
+			t.Fatalf("expected ErrInvalidSort for %v, got %v", sort, err)
+		}
+	}
+
+	if _, err := svc.ListTasks(context.Background(), ListOptions{Sort: []string{"-due_at", "priority", "title"}}); err != nil {
+		t.Fatalf("unexpected error for valid sort: %v", err)
+	}
+}
+
+func TestListTasks_SearchDefaultsToRankAndRejectsPunctuation(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := NewTaskService(repo)
+
+	if _, err := svc.ListTasks(context.Background(), ListOptions{Search: "%_!"}); !errors.Is(err, ErrInvalidSearch) {
+		t.Fatalf("expected ErrInvalidSearch, got %v", err)
+	}
+	if _, err := svc.ListTasks(context.Background(), ListOptions{Sort: []string{"-rank"}}); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort for rank without search, got %v", err)
+	}
+
+	if _, err := svc.CreateTask(context.Background(), CreateTaskInput{Title: "Fix login bug"}); err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+
+	page, err := svc.ListTasks(context.Background(), ListOptions{Search: "login bug", PageSize: "1"})
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(page.Tasks) != 1 || page.NextCursor != "" || page.PrevCursor != "" {
+		t.Fatalf("ranked results should not carry keyset cursors")
+	}
+}
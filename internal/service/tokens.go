@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	DefaultTokenIssuer     = "taskapi"
+)
+
+var (
+	ErrInvalidToken        = errors.New("access token is invalid or expired")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrUnauthenticated     = errors.New("authentication required")
+	ErrSessionRequired     = errors.New("this endpoint needs a login session, not an api key")
+)
+
+type TokenOptions struct {
+	Issuer string
+	// AccessTTL is how long an access token is accepted
+	AccessTTL time.Duration
+	// RefreshTTL is how long a session survives without being refreshed
+	RefreshTTL time.Duration
+}
+
+// TokenPair is what a client gets on login and on every refresh.
+type TokenPair struct {
+	AccessToken  string       `json:"access_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int          `json:"expires_in"`
+	RefreshToken string       `json:"refresh_token"`
+	User         *models.User `json:"user,omitempty"`
+}
+
+// TokenService issues short-lived JWT access tokens and rotating refresh
+// tokens. Every login is a session; an access token is only accepted while
+// its session is live, so revoking a session takes effect immediately.
+type TokenService struct {
+	users    *UserService
+	sessions repository.SessionRepository
+	keys     *auth.KeySet
+	opts     TokenOptions
+	now      func() time.Time
+}
+
+func NewTokenService(users *UserService, sessions repository.SessionRepository, keys *auth.KeySet, opts TokenOptions) *TokenService {
+	if opts.Issuer == "" {
+		opts.Issuer = DefaultTokenIssuer
+	}
+	if opts.AccessTTL <= 0 {
+		opts.AccessTTL = DefaultAccessTokenTTL
+	}
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = DefaultRefreshTokenTTL
+	}
+	return &TokenService{
+		users:    users,
+		sessions: sessions,
+		keys:     keys,
+		opts:     opts,
+		now:      time.Now,
+	}
+}
+
+// Login checks the credentials and starts a new session.
+func (s *TokenService) Login(ctx context.Context, in LoginInput) (*TokenPair, error) {
+	u, err := s.users.Authenticate(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.sessions.Create(ctx, u.ID, hashToken(refresh), s.now().Add(s.opts.RefreshTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := s.issue(session, refresh)
+	if err != nil {
+		return nil, err
+	}
+	pair.User = u
+	return pair, nil
+}
+
+// Refresh swaps a refresh token for a new pair. Each refresh token works
+// once; presenting one a second time revokes the session, since either the
+// client or an attacker is holding a stolen copy.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	next, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.Rotate(ctx, hashToken(refreshToken), hashToken(next), s.now().Add(s.opts.RefreshTTL))
+	if errors.Is(err, models.ErrRefreshTokenInvalid) || errors.Is(err, models.ErrRefreshTokenReused) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issue(session, next)
+}
+
+func (s *TokenService) issue(session *models.Session, refresh string) (*TokenPair, error) {
+	now := s.now()
+	access, err := s.keys.Sign(auth.Claims{
+		Issuer:    s.opts.Issuer,
+		Subject:   session.UserID,
+		ID:        uuid.NewString(),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.opts.AccessTTL).Unix(),
+		SessionID: session.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.opts.AccessTTL.Seconds()),
+		RefreshToken: refresh,
+	}, nil
+}
+
+// VerifyAccessToken checks the token's signature, expiry and issuer, and
+// that its session has not been revoked.
+func (s *TokenService) VerifyAccessToken(ctx context.Context, token string) (*auth.Principal, error) {
+	claims, err := s.keys.Verify(token, s.now())
+	if err != nil || claims.Issuer != s.opts.Issuer || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	session, err := s.sessions.GetByID(ctx, claims.SessionID)
+	if errors.Is(err, models.ErrSessionNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.RevokedAt != nil || session.UserID != claims.Subject {
+		return nil, ErrInvalidToken
+	}
+
+	return &auth.Principal{UserID: claims.Subject, SessionID: session.ID}, nil
+}
+
+// Logout revokes the caller's session; its access and refresh tokens stop
+// working straight away.
+func (s *TokenService) Logout(ctx context.Context) error {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	err = s.sessions.Revoke(ctx, p.SessionID)
+	if errors.Is(err, models.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// LogoutAll revokes every session of the caller.
+func (s *TokenService) LogoutAll(ctx context.Context) error {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, p.UserID)
+}
+
+// sessionPrincipal is the caller when they logged in with a password.
+// Managing sessions and keys is off limits to API keys, so a leaked key
+// can't be used to mint more.
+func sessionPrincipal(ctx context.Context) (*auth.Principal, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return nil, ErrUnauthenticated
+	}
+	if p.SessionID == "" {
+		return nil, ErrSessionRequired
+	}
+	return p, nil
+}
+
+// Me returns the caller's account.
+func (s *TokenService) Me(ctx context.Context) (*models.User, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return nil, ErrUnauthenticated
+	}
+	return s.users.GetUser(ctx, p.UserID)
+}
+
+// PublicKeys is the JWK Set of the asymmetric verification keys.
+func (s *TokenService) PublicKeys() ([]byte, error) {
+	return s.keys.PublicJWKS()
+}
+
+// PurgeSessions deletes expired and revoked sessions with their tokens.
+func (s *TokenService) PurgeSessions(ctx context.Context) (int64, error) {
+	return s.sessions.DeleteExpired(ctx)
+}
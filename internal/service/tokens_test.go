@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeSessionRepo struct {
+	sessions map[string]*models.Session
+	// tokens maps a refresh token hash to its session; used ones are kept
+	tokens map[string]string
+	used   map[string]bool
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{
+		sessions: map[string]*models.Session{},
+		tokens:   map[string]string{},
+		used:     map[string]bool{},
+	}
+}
+
+func (f *fakeSessionRepo) Create(_ context.Context, userID, tokenHash string, expiresAt time.Time) (*models.Session, error) {
+	s := &models.Session{ID: fmt.Sprintf("s%d", len(f.sessions)+1), UserID: userID, CreatedAt: time.Now(), ExpiresAt: expiresAt}
+	f.sessions[s.ID] = s
+	f.tokens[tokenHash] = s.ID
+	copy := *s
+	return &copy, nil
+}
+
+func (f *fakeSessionRepo) GetByID(_ context.Context, id string) (*models.Session, error) {
+	s, ok := f.sessions[id]
+	if !ok {
+		return nil, models.ErrSessionNotFound
+	}
+	copy := *s
+	return &copy, nil
+}
+
+func (f *fakeSessionRepo) Rotate(_ context.Context, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	id, ok := f.tokens[oldHash]
+	if !ok {
+		return nil, models.ErrRefreshTokenInvalid
+	}
+	s := f.sessions[id]
+	if s.RevokedAt != nil || !s.ExpiresAt.After(time.Now()) {
+		return nil, models.ErrRefreshTokenInvalid
+	}
+	if f.used[oldHash] {
+		now := time.Now()
+		s.RevokedAt = &now
+		return nil, models.ErrRefreshTokenReused
+	}
+	f.used[oldHash] = true
+	f.tokens[newHash] = id
+	s.ExpiresAt = expiresAt
+	copy := *s
+	return &copy, nil
+}
+
+func (f *fakeSessionRepo) Revoke(_ context.Context, id string) error {
+	s, ok := f.sessions[id]
+	if !ok {
+		return models.ErrSessionNotFound
+	}
+	if s.RevokedAt == nil {
+		now := time.Now()
+		s.RevokedAt = &now
+	}
+	return nil
+}
+
+func (f *fakeSessionRepo) RevokeAll(ctx context.Context, userID string) error {
+	for id, s := range f.sessions {
+		if s.UserID == userID {
+			_ = f.Revoke(ctx, id)
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessionRepo) DeleteExpired(context.Context) (int64, error) {
+	var n int64
+	for id, s := range f.sessions {
+		if s.RevokedAt != nil || !s.ExpiresAt.After(time.Now()) {
+			delete(f.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newTestTokenService(t *testing.T) *TokenService {
+	t.Helper()
+	key, err := auth.GenerateHMACKey("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.NewKeySet("", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := NewUserService(newFakeUserRepo(), nil)
+	if _, err := users.Register(context.Background(), RegisterInput{Email: "ada@example.com", Password: "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenService(users, newFakeSessionRepo(), keys, TokenOptions{})
+}
+
+func login(t *testing.T, svc *TokenService) *TokenPair {
+	t.Helper()
+	pair, err := svc.Login(context.Background(), LoginInput{Email: "ada@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return pair
+}
+
+func TestLoginIssuesVerifiableTokens(t *testing.T) {
+	svc := newTestTokenService(t)
+	ctx := context.Background()
+
+	pair := login(t, svc)
+	if pair.TokenType != "Bearer" || pair.ExpiresIn != int(DefaultAccessTokenTTL.Seconds()) || pair.RefreshToken == "" {
+		t.Fatalf("unexpected pair %+v", pair)
+	}
+	p, err := svc.VerifyAccessToken(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if p.UserID != pair.User.ID || p.SessionID == "" {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+
+	if _, err := svc.Login(ctx, LoginInput{Email: "ada@example.com", Password: "wrong horse"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(ctx, "not.a.token"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestAccessTokenExpires(t *testing.T) {
+	svc := newTestTokenService(t)
+	pair := login(t, svc)
+
+	svc.now = func() time.Time { return time.Now().Add(DefaultAccessTokenTTL + time.Minute) }
+	if _, err := svc.VerifyAccessToken(context.Background(), pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestAccessTokenFromOtherIssuer(t *testing.T) {
+	svc := newTestTokenService(t)
+	pair := login(t, svc)
+
+	svc.opts.Issuer = "someone-else"
+	if _, err := svc.VerifyAccessToken(context.Background(), pair.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRefreshRotates(t *testing.T) {
+	svc := newTestTokenService(t)
+	ctx := context.Background()
+	first := login(t, svc)
+
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.User != nil {
+		t.Fatalf("unexpected pair %+v", second)
+	}
+	p, err := svc.VerifyAccessToken(ctx, second.AccessToken)
+	if err != nil {
+		t.Fatalf("verify refreshed token: %v", err)
+	}
+	if p.UserID != first.User.ID {
+		t.Fatalf("refreshed token for %q, want %q", p.UserID, first.User.ID)
+	}
+
+	if _, err := svc.Refresh(ctx, second.RefreshToken); err != nil {
+		t.Fatalf("second refresh: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, "made up"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesSession(t *testing.T) {
+	svc := newTestTokenService(t)
+	ctx := context.Background()
+	first := login(t, svc)
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reused token accepted: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("session survived token reuse: %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(ctx, second.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("access token survived token reuse: %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	svc := newTestTokenService(t)
+	ctx := context.Background()
+
+	if err := svc.Logout(ctx); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	a, b := login(t, svc), login(t, svc)
+	p, err := svc.VerifyAccessToken(ctx, a.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Logout(auth.WithPrincipal(ctx, p)); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(ctx, a.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("access token survived logout: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, a.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refresh token survived logout: %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(ctx, b.AccessToken); err != nil {
+		t.Fatalf("other session logged out too: %v", err)
+	}
+
+	if err := svc.LogoutAll(auth.WithPrincipal(ctx, p)); err != nil {
+		t.Fatalf("logout all: %v", err)
+	}
+	if _, err := svc.VerifyAccessToken(ctx, b.AccessToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("session survived logout-all: %v", err)
+	}
+}
+
+func TestMe(t *testing.T) {
+	svc := newTestTokenService(t)
+	ctx := context.Background()
+	pair := login(t, svc)
+
+	if _, err := svc.Me(ctx); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+	p, _ := svc.VerifyAccessToken(ctx, pair.AccessToken)
+	u, err := svc.Me(auth.WithPrincipal(ctx, p))
+	if err != nil || u.Email != "ada@example.com" {
+		t.Fatalf("me: %v %+v", err, u)
+	}
+}
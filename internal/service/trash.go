@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// DefaultTrashRetention is how long trashed tasks are kept before the purge job removes them.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ListTrash lists trashed tasks with the same options as ListTasks.
+func (s *TaskService) ListTrash(ctx context.Context, in ListOptions) (*TaskPage, error) {
+	in.Trashed = true
+	return s.ListTasks(ctx, in)
+}
+
+// RestoreTask brings a trashed task back, together with the subtasks that
+// were trashed along with it.
+func (s *TaskService) RestoreTask(ctx context.Context, id string) (*models.Task, error) {
+	if err := s.Authorize(ctx, id, models.RoleEditor); err != nil {
+		return nil, err
+	}
+	task, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// PurgeTask permanently deletes a task that is already in the trash. Only
+// owners may do this.
+func (s *TaskService) PurgeTask(ctx context.Context, id string) error {
+	if err := s.Authorize(ctx, id, models.RoleOwner); err != nil {
+		return err
+	}
+	if err := s.repo.Purge(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// EmptyTrash permanently deletes the trashed tasks the caller owns, or
+// the whole trash when access control is off.
+func (s *TaskService) EmptyTrash(ctx context.Context) (int64, error) {
+	userID, err := s.caller(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if userID == "" {
+		return s.PurgeTrash(ctx, 0)
+	}
+
+	filter := repository.ListFilter{
+		Trashed:   true,
+		Archived:  repository.ArchivedInclude,
+		VisibleTo: userID,
+		MinRole:   models.RoleOwner,
+	}
+	var purged int64
+	for {
+		tasks, err := s.repo.List(ctx, filter, repository.Pagination{Limit: 100})
+		if err != nil || len(tasks) == 0 {
+			return purged, err
+		}
+		for _, t := range tasks {
+			// purging a parent takes its trashed subtasks along
+			err := s.repo.Purge(ctx, t.ID)
+			if err != nil && !errors.Is(err, models.ErrNotFound) {
+				return purged, err
+			}
+			if err == nil {
+				purged++
+			}
+		}
+	}
+}
+
+// PurgeTrash permanently deletes tasks trashed more than olderThan ago;
+// zero empties the trash. It is meant for the retention job and does not
+// check roles.
+func (s *TaskService) PurgeTrash(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return s.repo.PurgeTrash(ctx, time.Now().Add(-olderThan))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeleteTask_CascadeGoesToTrashAndRestores(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	root := createChild(t, svc, "root", nil)
+	child := createChild(t, svc, "child", root)
+
+	if err := svc.DeleteTask(ctx, root.ID, DeleteOptions{Children: ChildrenCascade}); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := svc.GetTask(ctx, child.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected trashed child to be hidden, got %v", err)
+	}
+	trash, err := svc.ListTrash(ctx, ListOptions{})
+	if err != nil || trash.Total != 2 {
+		t.Fatalf("expected 2 trashed tasks, got %+v (err %v)", trash, err)
+	}
+
+	if _, err := svc.RestoreTask(ctx, root.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	got, err := svc.GetTask(ctx, child.ID)
+	if err != nil || got.ParentID == nil || *got.ParentID != root.ID {
+		t.Fatalf("expected child to come back under root, got %+v (err %v)", got, err)
+	}
+	if _, err := svc.RestoreTask(ctx, root.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected restoring a live task to fail, got %v", err)
+	}
+}
+
+func TestRestoreTask_DetachesFromTrashedParent(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	root := createChild(t, svc, "root", nil)
+	child := createChild(t, svc, "child", root)
+
+	if err := svc.DeleteTask(ctx, child.ID, DeleteOptions{}); err != nil {
+		t.Fatalf("delete child: %v", err)
+	}
+	if err := svc.DeleteTask(ctx, root.ID, DeleteOptions{}); err != nil {
+		t.Fatalf("delete root: %v", err)
+	}
+
+	got, err := svc.RestoreTask(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if got.ParentID != nil {
+		t.Fatalf("expected child to be restored at the top level, got parent %v", *got.ParentID)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	kept := createChild(t, svc, "kept", nil)
+	purged := createChild(t, svc, "purged", nil)
+
+	if err := svc.PurgeTask(ctx, purged.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected purging a live task to fail, got %v", err)
+	}
+	for _, task := range []string{kept.ID, purged.ID} {
+		if err := svc.DeleteTask(ctx, task, DeleteOptions{}); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+	}
+	if err := svc.PurgeTask(ctx, purged.ID); err != nil {
+		t.Fatalf("PurgeTask: %v", err)
+	}
+	if _, err := svc.RestoreTask(ctx, purged.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected purged task to be gone, got %v", err)
+	}
+
+	if n, _ := svc.PurgeTrash(ctx, time.Hour); n != 0 {
+		t.Fatalf("expected fresh trash to survive the retention period, purged %d", n)
+	}
+	if n, _ := svc.PurgeTrash(ctx, 0); n != 1 {
+		t.Fatalf("expected the remaining trashed task to be purged, purged %d", n)
+	}
+}
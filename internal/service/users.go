@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+// DefaultResetTokenTTL is how long a password reset token stays valid.
+const DefaultResetTokenTTL = time.Hour
+
+const (
+	minPasswordLength = 8
+	// argon2 takes any length, but hashing megabytes on every login is a
+	// cheap denial of service
+	maxPasswordLength = 256
+)
+
+var (
+	ErrInvalidEmail       = errors.New("email must be a valid address")
+	ErrInvalidPassword    = errors.New("password must be between 8 and 256 characters")
+	ErrInvalidUserName    = errors.New("name must be <= 100 characters")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidResetToken  = errors.New("reset token is invalid or expired")
+	ErrUserNotFound       = errors.New("user not found")
+)
+
+// ResetSender delivers password reset tokens to users, e.g. by email.
+type ResetSender interface {
+	SendPasswordReset(ctx context.Context, u *models.User, token string) error
+}
+
+// LogResetSender writes reset tokens to the server log. It is meant for
+// development only, as anyone reading the log can take over accounts.
+type LogResetSender struct{}
+
+func (LogResetSender) SendPasswordReset(_ context.Context, u *models.User, token string) error {
+	log.Printf("password reset for %s: token %s", u.Email, token)
+	return nil
+}
+
+type RegisterInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+}
+
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordInput struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type UserService struct {
+	users    repository.UserRepository
+	sender   ResetSender
+	resetTTL time.Duration
+	// dummyHash is verified against when the email is unknown, so a
+	// failed login takes as long either way
+	dummyHash string
+}
+
+// NewUserService takes a nil sender when reset tokens can't be delivered;
+// reset requests are then accepted but do nothing.
+func NewUserService(users repository.UserRepository, sender ResetSender) *UserService {
+	dummy, err := hashPassword("not a real password")
+	if err != nil {
+		panic(err)
+	}
+	return &UserService{
+		users:     users,
+		sender:    sender,
+		resetTTL:  DefaultResetTokenTTL,
+		dummyHash: dummy,
+	}
+}
+
+func normalizeEmail(email string) (string, error) {
+	e := strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(e)
+	if err != nil || addr.Address != e || len(e) > 254 {
+		return "", ErrInvalidEmail
+	}
+	return e, nil
+}
+
+func validatePassword(password string) error {
+	// both limits count characters, as the error message says
+	n := utf8.RuneCountInString(password)
+	if n < minPasswordLength || n > maxPasswordLength {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+func (s *UserService) Register(ctx context.Context, in RegisterInput) (*models.User, error) {
+	email, err := normalizeEmail(in.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePassword(in.Password); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if utf8.RuneCountInString(name) > 100 {
+		return nil, ErrInvalidUserName
+	}
+
+	hash, err := hashPassword(in.Password)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.Create(ctx, &models.User{Email: email, Name: name, PasswordHash: hash})
+	if errors.Is(err, models.ErrConflict) {
+		return nil, ErrEmailTaken
+	}
+	return u, err
+}
+
+// Authenticate checks an email and password pair. Unknown emails and wrong
+// passwords both come back as ErrInvalidCredentials.
+func (s *UserService) Authenticate(ctx context.Context, in LoginInput) (*models.User, error) {
+	email, err := normalizeEmail(in.Email)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, models.ErrUserNotFound) {
+		_, _, _ = verifyPassword(s.dummyHash, in.Password)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, rehash, err := verifyPassword(u.PasswordHash, in.Password)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	if rehash {
+		if hash, err := hashPassword(in.Password); err == nil && s.users.UpdatePassword(ctx, u.ID, hash) == nil {
+			u.PasswordHash = hash
+		}
+	}
+	return u, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	u, err := s.users.GetByID(ctx, id)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return u, err
+}
+
+// ChangePassword requires the current password. It voids any reset tokens
+// still outstanding and signs the user out everywhere.
+func (s *UserService) ChangePassword(ctx context.Context, in ChangePasswordInput) error {
+	if err := validatePassword(in.NewPassword); err != nil {
+		return err
+	}
+	u, err := s.Authenticate(ctx, LoginInput{Email: in.Email, Password: in.CurrentPassword})
+	if err != nil {
+		return err
+	}
+	return s.setPassword(ctx, u.ID, in.NewPassword)
+}
+
+// RequestPasswordReset sends a single-use token to the account's owner.
+// It reports success for unknown emails too, so it can't be used to find
+// out who has an account.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	e, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	u, err := s.users.GetByEmail(ctx, e)
+	if errors.Is(err, models.ErrUserNotFound) || s.sender == nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	if err := s.users.CreateResetToken(ctx, u.ID, hashToken(token), time.Now().Add(s.resetTTL)); err != nil {
+		return err
+	}
+	return s.sender.SendPasswordReset(ctx, u, token)
+}
+
+func (s *UserService) ResetPassword(ctx context.Context, in ResetPasswordInput) error {
+	if err := validatePassword(in.NewPassword); err != nil {
+		return err
+	}
+	userID, err := s.users.ConsumeResetToken(ctx, hashToken(in.Token))
+	if errors.Is(err, models.ErrResetTokenInvalid) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	return s.setPassword(ctx, userID, in.NewPassword)
+}
+
+func (s *UserService) setPassword(ctx context.Context, userID, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.users.UpdatePassword(ctx, userID, hash)
+}
+
+// newToken returns an opaque random token for a client to hold on to.
+// Only its hashToken is stored.
+func newToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/internal/audit"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeUserRepo struct {
+	users  map[string]models.User
+	tokens map[string]resetToken
+}
+
+type resetToken struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: map[string]models.User{}, tokens: map[string]resetToken{}}
+}
+
+func (f *fakeUserRepo) Create(_ context.Context, u *models.User) (*models.User, error) {
+	for _, existing := range f.users {
+		if existing.Email == u.Email {
+			return nil, models.ErrConflict
+		}
+	}
+	copy := *u
+	copy.ID = fmt.Sprintf("u%d", len(f.users)+1)
+	f.users[copy.ID] = copy
+	return &copy, nil
+}
+
+func (f *fakeUserRepo) GetByID(_ context.Context, id string) (*models.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	return &u, nil
+}
+
+func (f *fakeUserRepo) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, models.ErrUserNotFound
+}
+
+func (f *fakeUserRepo) UpdatePassword(_ context.Context, id, hash string) error {
+	u, ok := f.users[id]
+	if !ok {
+		return models.ErrUserNotFound
+	}
+	u.PasswordHash = hash
+	f.users[id] = u
+	for k, t := range f.tokens {
+		if t.userID == id {
+			delete(f.tokens, k)
+		}
+	}
+	return nil
+}
+
+func (f *fakeUserRepo) CreateResetToken(_ context.Context, userID, tokenHash string, expiresAt time.Time) error {
+	f.tokens[tokenHash] = resetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeUserRepo) ConsumeResetToken(_ context.Context, tokenHash string) (string, error) {
+	t, ok := f.tokens[tokenHash]
+	delete(f.tokens, tokenHash)
+	if !ok || time.Now().After(t.expiresAt) {
+		return "", models.ErrResetTokenInvalid
+	}
+	return t.userID, nil
+}
+
+type captureSender struct {
+	token string
+}
+
+func (c *captureSender) SendPasswordReset(_ context.Context, _ *models.User, token string) error {
+	c.token = token
+	return nil
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	svc := NewUserService(newFakeUserRepo(), nil)
+
+	u, err := svc.Register(ctx, RegisterInput{Email: " Ada@Example.com ", Password: "correct horse", Name: "Ada"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if u.Email != "ada@example.com" {
+		t.Fatalf("email not normalized: %q", u.Email)
+	}
+	if !strings.HasPrefix(u.PasswordHash, "$argon2id$") {
+		t.Fatalf("unexpected hash %q", u.PasswordHash)
+	}
+
+	if _, err := svc.Register(ctx, RegisterInput{Email: "ada@example.com", Password: "another one"}); !errors.Is(err, ErrEmailTaken) {
+		t.Fatalf("expected ErrEmailTaken, got %v", err)
+	}
+
+	got, err := svc.Authenticate(ctx, LoginInput{Email: "ADA@example.com", Password: "correct horse"})
+	if err != nil || got.ID != u.ID {
+		t.Fatalf("login: %v %+v", err, got)
+	}
+	for _, in := range []LoginInput{
+		{Email: "ada@example.com", Password: "wrong horse"},
+		{Email: "nobody@example.com", Password: "correct horse"},
+		{Email: "not an email", Password: "correct horse"},
+	} {
+		if _, err := svc.Authenticate(ctx, in); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("%+v: expected ErrInvalidCredentials, got %v", in, err)
+		}
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	svc := NewUserService(newFakeUserRepo(), nil)
+	cases := []struct {
+		in   RegisterInput
+		want error
+	}{
+		{RegisterInput{Email: "nope", Password: "long enough"}, ErrInvalidEmail},
+		{RegisterInput{Email: "Ada <ada@example.com>", Password: "long enough"}, ErrInvalidEmail},
+		{RegisterInput{Email: "ada@example.com", Password: "short"}, ErrInvalidPassword},
+		{RegisterInput{Email: "ada@example.com", Password: strings.Repeat("x", 257)}, ErrInvalidPassword},
+		{RegisterInput{Email: "ada@example.com", Password: strings.Repeat("é", 257)}, ErrInvalidPassword},
+		{RegisterInput{Email: "ada@example.com", Password: "long enough", Name: strings.Repeat("n", 101)}, ErrInvalidUserName},
+	}
+	for _, c := range cases {
+		if _, err := svc.Register(context.Background(), c.in); !errors.Is(err, c.want) {
+			t.Errorf("%+v: expected %v, got %v", c.in, c.want, err)
+		}
+	}
+}
+
+func TestRegisterCountsPasswordCharacters(t *testing.T) {
+	svc := NewUserService(newFakeUserRepo(), nil)
+
+	// 256 characters, but 512 bytes
+	if _, err := svc.Register(context.Background(), RegisterInput{Email: "ada@example.com", Password: strings.Repeat("é", 256)}); err != nil {
+		t.Fatalf("expected a 256-character password to be accepted, got %v", err)
+	}
+}
+
+func TestLoginUpgradesBcryptHash(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeUserRepo()
+	legacy, err := bcrypt.GenerateFromPassword([]byte("legacy secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := repo.Create(ctx, &models.User{Email: "old@example.com", PasswordHash: string(legacy)})
+	svc := NewUserService(repo, nil)
+
+	if _, err := svc.Authenticate(ctx, LoginInput{Email: "old@example.com", Password: "legacy secret"}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if h := repo.users[u.ID].PasswordHash; !strings.HasPrefix(h, "$argon2id$") {
+		t.Fatalf("hash not upgraded: %q", h)
+	}
+	if _, err := svc.Authenticate(ctx, LoginInput{Email: "old@example.com", Password: "legacy secret"}); err != nil {
+		t.Fatalf("login after upgrade: %v", err)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	svc := NewUserService(newFakeUserRepo(), nil)
+	if _, err := svc.Register(ctx, RegisterInput{Email: "ada@example.com", Password: "first password"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := svc.ChangePassword(ctx, ChangePasswordInput{Email: "ada@example.com", CurrentPassword: "wrong", NewPassword: "second password"})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	err = svc.ChangePassword(ctx, ChangePasswordInput{Email: "ada@example.com", CurrentPassword: "first password", NewPassword: "second password"})
+	if err != nil {
+		t.Fatalf("change: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, LoginInput{Email: "ada@example.com", Password: "first password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("old password still works: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, LoginInput{Email: "ada@example.com", Password: "second password"}); err != nil {
+		t.Fatalf("new password rejected: %v", err)
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	sender := &captureSender{}
+	svc := NewUserService(newFakeUserRepo(), sender)
+	if _, err := svc.Register(ctx, RegisterInput{Email: "ada@example.com", Password: "forgotten it"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.RequestPasswordReset(ctx, "nobody@example.com"); err != nil || sender.token != "" {
+		t.Fatalf("unknown email: err=%v token=%q", err, sender.token)
+	}
+	if err := svc.RequestPasswordReset(ctx, "ada@example.com"); err != nil || sender.token == "" {
+		t.Fatalf("request: err=%v token=%q", err, sender.token)
+	}
+
+	if err := svc.ResetPassword(ctx, ResetPasswordInput{Token: "bogus", NewPassword: "brand new one"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, ResetPasswordInput{Token: sender.token, NewPassword: "brand new one"}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, LoginInput{Email: "ada@example.com", Password: "brand new one"}); err != nil {
+		t.Fatalf("login after reset: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, ResetPasswordInput{Token: sender.token, NewPassword: "yet another"}); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("token reused: %v", err)
+	}
+}
+
+func TestCreateTaskRecordsCreator(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+
+	anon, err := svc.CreateTask(context.Background(), CreateTaskInput{Title: "anonymous"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anon.CreatedBy != nil {
+		t.Fatalf("expected no creator, got %q", *anon.CreatedBy)
+	}
+
+	task, err := svc.CreateTask(audit.WithActor(context.Background(), "u1"), CreateTaskInput{Title: "owned"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.CreatedBy == nil || *task.CreatedBy != "u1" {
+		t.Fatalf("expected creator u1, got %v", task.CreatedBy)
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var ErrPreconditionFailed = errors.New("task has been modified; fetch it again and retry")
+
+// VersionMatch lists the task versions a client is willing to overwrite,
+// typically taken from an If-Match header. Nil matches any version.
+type VersionMatch []int
+
+func (m VersionMatch) allows(version int) bool {
+	return m == nil || slices.Contains(m, version)
+}
+
+func checkVersion(t *models.Task, m VersionMatch) error {
+	if !m.allows(t.Version) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
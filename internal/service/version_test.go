@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func TestUpdateTask_IfMatch(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	task := createChild(t, svc, "versioned", nil)
+	if task.Version != 1 {
+		t.Fatalf("expected a new task at version 1, got %d", task.Version)
+	}
+
+	title := "renamed"
+	updated, err := svc.UpdateTask(ctx, task.ID, UpdateTaskInput{Title: &title, IfMatch: VersionMatch{1}})
+	if err != nil {
+		t.Fatalf("UpdateTask with current version: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("expected version to be bumped to 2, got %d", updated.Version)
+	}
+
+	title = "lost update"
+	if _, err := svc.UpdateTask(ctx, task.ID, UpdateTaskInput{Title: &title, IfMatch: VersionMatch{1}}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected stale If-Match to fail, got %v", err)
+	}
+	if _, err := svc.UpdateTask(ctx, task.ID, UpdateTaskInput{Title: &title, IfMatch: VersionMatch{}}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected If-Match without usable tags to fail, got %v", err)
+	}
+
+	got, _ := svc.GetTask(ctx, task.ID)
+	if got.Title != "renamed" {
+		t.Fatalf("expected the stale write to be discarded, got %q", got.Title)
+	}
+}
+
+func TestDeleteTask_IfMatch(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	ctx := context.Background()
+	task := createChild(t, svc, "versioned", nil)
+
+	if err := svc.DeleteTask(ctx, task.ID, DeleteOptions{IfMatch: VersionMatch{7}}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected stale If-Match to fail, got %v", err)
+	}
+	if err := svc.DeleteTask(ctx, task.ID, DeleteOptions{IfMatch: VersionMatch{3, 1}}); err != nil {
+		t.Fatalf("DeleteTask with current version: %v", err)
+	}
+}
+
+// racingRepo lets another writer update a task right after it was read.
+type racingRepo struct {
+	*fakeTaskRepo
+}
+
+func (r racingRepo) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	t, err := r.fakeTaskRepo.GetByID(ctx, id)
+	if err == nil {
+		racer := *t
+		_, err = r.fakeTaskRepo.Update(ctx, &racer)
+	}
+	return t, err
+}
+
+func TestDeleteTask_IfMatchLosesRaceWithUpdate(t *testing.T) {
+	repo := newFakeTaskRepo()
+	task := createChild(t, NewTaskService(repo), "versioned", nil)
+	svc := NewTaskService(racingRepo{repo})
+
+	if err := svc.DeleteTask(context.Background(), task.ID, DeleteOptions{IfMatch: VersionMatch{1}}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if repo.store[task.ID].DeletedAt != nil {
+		t.Fatalf("task was deleted despite the concurrent update")
+	}
+}
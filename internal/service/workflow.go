@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+var ErrInvalidTransition = errors.New("status transition is not allowed")
+
+// Transition is one edge of a Workflow. Each side matches by status name,
+// by category, or both; an empty field matches anything. Reopen edges lead
+// back out of a finished state and must be asked for explicitly.
+type Transition struct {
+	From, To                 models.TaskStatus
+	FromCategory, ToCategory models.StatusCategory
+	Reopen                   bool
+}
+
+func (t Transition) matches(from, to models.StatusDefinition) bool {
+	return (t.From == "" || t.From == from.Name) &&
+		(t.To == "" || t.To == to.Name) &&
+		(t.FromCategory == "" || t.FromCategory == from.Category) &&
+		(t.ToCategory == "" || t.ToCategory == to.Category)
+}
+
+// Workflow is the graph of status transitions UpdateTask accepts.
+type Workflow struct {
+	transitions []Transition
+}
+
+func NewWorkflow(transitions ...Transition) Workflow {
+	return Workflow{transitions: transitions}
+}
+
+// DefaultWorkflow moves not started -> active -> closed, allows hopping
+// between active statuses (e.g. in_progress -> review), and reopens closed
+// tasks back to either earlier category. For the built-in statuses that
+// is todo -> in_progress -> done.
+func DefaultWorkflow() Workflow {
+	return NewWorkflow(
+		Transition{FromCategory: models.CategoryNotStarted, ToCategory: models.CategoryActive},
+		Transition{FromCategory: models.CategoryActive, ToCategory: models.CategoryActive},
+		Transition{FromCategory: models.CategoryActive, ToCategory: models.CategoryClosed},
+		Transition{FromCategory: models.CategoryClosed, ToCategory: models.CategoryNotStarted, Reopen: true},
+		Transition{FromCategory: models.CategoryClosed, ToCategory: models.CategoryActive, Reopen: true},
+	)
+}
+
+// WithWorkflow replaces the default transition graph.
+func WithWorkflow(w Workflow) Option {
+	return func(s *TaskService) {
+		s.workflow = w
+	}
+}
+
+// Check allows staying put, any forward edge, and reopen edges only when
+// reopen is set.
+func (w Workflow) Check(from, to models.StatusDefinition, reopen bool) error {
+	if from.Name == to.Name {
+		return nil
+	}
+	for _, t := range w.transitions {
+		if t.matches(from, to) && (!t.Reopen || reopen) {
+			return nil
+		}
+	}
+	return ErrInvalidTransition
+}
+
+// stampStatus keeps started_at/completed_at in line with the category of
+// the status a task has just moved to.
+func stampStatus(t *models.Task, category models.StatusCategory, now time.Time) {
+	switch category {
+	case models.CategoryNotStarted:
+		t.StartedAt = nil
+		t.CompletedAt = nil
+	case models.CategoryActive:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+		t.CompletedAt = nil
+	case models.CategoryClosed:
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+		if t.CompletedAt == nil {
+			t.CompletedAt = &now
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+func setStatus(svc *TaskService, id string, status models.TaskStatus, reopen bool) (models.Task, error) {
+	s := string(status)
+	return svc.UpdateTask(context.Background(), id, UpdateTaskInput{Status: &s, Reopen: reopen})
+}
+
+func TestUpdateTask_EnforcesDefaultWorkflow(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo())
+	task := createChild(t, svc, "workflow", nil)
+
+	if _, err := setStatus(svc, task.ID, models.StatusDone, false); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected todo -> done to be rejected, got %v", err)
+	}
+
+	started, err := setStatus(svc, task.ID, models.StatusInProgress, false)
+	if err != nil {
+		t.Fatalf("todo -> in_progress: %v", err)
+	}
+	if started.StartedAt == nil || started.CompletedAt != nil {
+		t.Fatalf("expected started_at only, got started=%v completed=%v", started.StartedAt, started.CompletedAt)
+	}
+
+	done, err := setStatus(svc, task.ID, models.StatusDone, false)
+	if err != nil {
+		t.Fatalf("in_progress -> done: %v", err)
+	}
+	if done.CompletedAt == nil || !done.StartedAt.Equal(*started.StartedAt) {
+		t.Fatalf("expected completed_at and unchanged started_at, got %+v", done)
+	}
+
+	if _, err := setStatus(svc, task.ID, models.StatusTodo, false); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected reopen without flag to be rejected, got %v", err)
+	}
+
+	reopened, err := setStatus(svc, task.ID, models.StatusTodo, true)
+	if err != nil {
+		t.Fatalf("explicit reopen: %v", err)
+	}
+	if reopened.StartedAt != nil || reopened.CompletedAt != nil {
+		t.Fatalf("expected timestamps to be cleared on reopen to todo, got %+v", reopened)
+	}
+}
+
+func TestUpdateTask_CustomWorkflow(t *testing.T) {
+	svc := NewTaskService(newFakeTaskRepo(), WithWorkflow(NewWorkflow(
+		Transition{From: models.StatusTodo, To: models.StatusDone},
+	)))
+	task := createChild(t, svc, "shortcut", nil)
+
+	if _, err := setStatus(svc, task.ID, models.StatusInProgress, false); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected todo -> in_progress to be rejected, got %v", err)
+	}
+	if _, err := setStatus(svc, task.ID, models.StatusDone, false); err != nil {
+		t.Fatalf("todo -> done: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/repository"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+const maxWorkspaceName = 100
+
+var (
+	ErrInvalidWorkspaceSlug = errors.New("slug must be 1-40 lowercase letters, digits or dashes, and can't start or end with a dash")
+	ErrInvalidWorkspaceName = errors.New("workspace name is required and must be <= 100 characters")
+	ErrInvalidWorkspaceRole = errors.New("role must be admin or member")
+	ErrWorkspaceNotFound    = errors.New("workspace not found")
+	ErrSlugTaken            = errors.New("a workspace with this slug already exists")
+	ErrLastAdmin            = errors.New("a workspace must keep at least one admin")
+	ErrNoWorkspace          = errors.New("no workspace selected; join or create one, or send X-Workspace")
+	ErrWrongWorkspace       = errors.New("this api key belongs to another workspace")
+)
+
+var workspaceSlug = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+type WorkspaceInput struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type WorkspaceMemberInput struct {
+	Email string               `json:"email"`
+	Role  models.WorkspaceRole `json:"role"`
+}
+
+type WorkspaceService struct {
+	repo repository.WorkspaceRepository
+}
+
+func NewWorkspaceService(repo repository.WorkspaceRepository) *WorkspaceService {
+	return &WorkspaceService{repo: repo}
+}
+
+// CreateWorkspace makes the caller the new workspace's admin.
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, in WorkspaceInput) (*models.Workspace, error) {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(in.Slug))
+	if !workspaceSlug.MatchString(slug) {
+		return nil, ErrInvalidWorkspaceSlug
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxWorkspaceName {
+		return nil, ErrInvalidWorkspaceName
+	}
+
+	w, err := s.repo.Create(ctx, &models.Workspace{Slug: slug, Name: name}, p.UserID)
+	if errors.Is(err, models.ErrConflict) {
+		return nil, ErrSlugTaken
+	}
+	return w, err
+}
+
+// ListWorkspaces lists the caller's workspaces, the default one first.
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]models.Workspace, error) {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ForUser(ctx, p.UserID)
+}
+
+// ResolveWorkspace picks the id of the workspace a request works in. An
+// API key always works in its own workspace. Users pick one by slug, or
+// get the first they joined. Anonymous callers and users in no workspace
+// get none, and workspace data stays out of their reach.
+func (s *WorkspaceService) ResolveWorkspace(ctx context.Context, slug string) (string, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return "", nil
+	}
+
+	if slug == "" {
+		if p.WorkspaceID != "" {
+			return p.WorkspaceID, nil
+		}
+		ws, err := s.repo.ForUser(ctx, p.UserID)
+		if err != nil || len(ws) == 0 {
+			return "", err
+		}
+		return ws[0].ID, nil
+	}
+
+	w, err := s.membership(ctx, slug, p.UserID)
+	if err != nil {
+		return "", err
+	}
+	if p.WorkspaceID != "" && p.WorkspaceID != w.ID {
+		return "", ErrWrongWorkspace
+	}
+	return w.ID, nil
+}
+
+// Each runs fn once per workspace, with the workspace in its context. It is
+// how background jobs reach data behind row-level security. fn keeps going
+// through the remaining workspaces after an error.
+func (s *WorkspaceService) Each(ctx context.Context, fn func(ctx context.Context) error) error {
+	ids, err := s.repo.IDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if err := fn(tenant.WithWorkspace(ctx, id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListMembers is open to every member of the workspace.
+func (s *WorkspaceService) ListMembers(ctx context.Context, slug string) ([]models.WorkspaceUser, error) {
+	w, err := s.authorize(ctx, slug, models.WorkspaceMember)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Members(ctx, w.ID)
+}
+
+// SetMember adds a user to the workspace or changes their role. Only
+// admins may do this.
+func (s *WorkspaceService) SetMember(ctx context.Context, slug string, in WorkspaceMemberInput) (*models.WorkspaceUser, error) {
+	w, err := s.authorize(ctx, slug, models.WorkspaceAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !in.Role.Valid() {
+		return nil, ErrInvalidWorkspaceRole
+	}
+	email, err := normalizeEmail(in.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Role != models.WorkspaceAdmin {
+		if err := s.keepAdmin(ctx, w.ID, func(m models.WorkspaceUser) bool { return m.Email == email }); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := s.repo.SetMember(ctx, w.ID, email, in.Role)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return m, err
+}
+
+// RemoveMember takes a user out of the workspace, along with their roles
+// on its tasks and their API keys for it. Admins may remove anyone, and
+// every member may leave.
+func (s *WorkspaceService) RemoveMember(ctx context.Context, slug, userID string) error {
+	need := models.WorkspaceAdmin
+	if p := auth.FromContext(ctx); p != nil && p.UserID == userID {
+		need = models.WorkspaceMember
+	}
+	w, err := s.authorize(ctx, slug, need)
+	if err != nil {
+		return err
+	}
+
+	if err := s.keepAdmin(ctx, w.ID, func(m models.WorkspaceUser) bool { return m.UserID == userID }); err != nil {
+		return err
+	}
+
+	err = s.repo.RemoveMember(ctx, w.ID, userID)
+	if errors.Is(err, models.ErrWorkspaceUserNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// AuthorizeCurrent checks the caller has at least need in the workspace
+// the request works in. API keys act with their user's role.
+func (s *WorkspaceService) AuthorizeCurrent(ctx context.Context, need models.WorkspaceRole) error {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return ErrUnauthenticated
+	}
+	workspaceID := tenant.WorkspaceID(ctx)
+	if workspaceID == "" {
+		return ErrNoWorkspace
+	}
+
+	members, err := s.repo.Members(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.UserID != p.UserID {
+			continue
+		}
+		if need == models.WorkspaceAdmin && m.Role != models.WorkspaceAdmin {
+			return ErrForbidden
+		}
+		return nil
+	}
+	return ErrForbidden
+}
+
+// authorize checks the caller belongs to the workspace with at least need.
+// Workspaces they don't belong to are reported as missing.
+func (s *WorkspaceService) authorize(ctx context.Context, slug string, need models.WorkspaceRole) (*models.Workspace, error) {
+	p, err := sessionPrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.membership(ctx, slug, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if need == models.WorkspaceAdmin && w.Role != models.WorkspaceAdmin {
+		return nil, ErrForbidden
+	}
+	return w, nil
+}
+
+func (s *WorkspaceService) membership(ctx context.Context, slug, userID string) (*models.Workspace, error) {
+	w, err := s.repo.Membership(ctx, slug, userID)
+	if errors.Is(err, models.ErrWorkspaceNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	return w, err
+}
+
+// keepAdmin refuses to demote or remove the member picked by is if they
+// are the workspace's only admin.
+func (s *WorkspaceService) keepAdmin(ctx context.Context, workspaceID string, is func(models.WorkspaceUser) bool) error {
+	members, err := s.repo.Members(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	admins, target := 0, false
+	for _, m := range members {
+		if m.Role == models.WorkspaceAdmin {
+			admins++
+			target = target || is(m)
+		}
+	}
+	if target && admins == 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Luc1808/TaskAPI/internal/auth"
+	"github.com/Luc1808/TaskAPI/internal/tenant"
+	"github.com/Luc1808/TaskAPI/pkg/models"
+)
+
+type fakeWorkspaceRepo struct {
+	workspaces []models.Workspace
+	// members holds each workspace's members in joining order
+	members map[string][]models.WorkspaceUser
+	// users maps emails to user ids
+	users map[string]string
+}
+
+func newFakeWorkspaceRepo() *fakeWorkspaceRepo {
+	return &fakeWorkspaceRepo{
+		members: map[string][]models.WorkspaceUser{},
+		users:   map[string]string{"alice@example.com": "alice", "bob@example.com": "bob", "carol@example.com": "carol"},
+	}
+}
+
+func (f *fakeWorkspaceRepo) Create(_ context.Context, w *models.Workspace, userID string) (*models.Workspace, error) {
+	for _, existing := range f.workspaces {
+		if existing.Slug == w.Slug {
+			return nil, models.ErrConflict
+		}
+	}
+	copy := *w
+	copy.ID = fmt.Sprintf("w%d", len(f.workspaces)+1)
+	f.workspaces = append(f.workspaces, copy)
+	f.members[copy.ID] = []models.WorkspaceUser{{WorkspaceID: copy.ID, UserID: userID, Role: models.WorkspaceAdmin}}
+	copy.Role = models.WorkspaceAdmin
+	return &copy, nil
+}
+
+func (f *fakeWorkspaceRepo) role(workspaceID, userID string) (models.WorkspaceRole, bool) {
+	for _, m := range f.members[workspaceID] {
+		if m.UserID == userID {
+			return m.Role, true
+		}
+	}
+	return "", false
+}
+
+func (f *fakeWorkspaceRepo) ForUser(_ context.Context, userID string) ([]models.Workspace, error) {
+	out := []models.Workspace{}
+	for _, w := range f.workspaces {
+		if role, ok := f.role(w.ID, userID); ok {
+			w.Role = role
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWorkspaceRepo) Membership(_ context.Context, slug, userID string) (*models.Workspace, error) {
+	for _, w := range f.workspaces {
+		if role, ok := f.role(w.ID, userID); ok && w.Slug == slug {
+			w.Role = role
+			return &w, nil
+		}
+	}
+	return nil, models.ErrWorkspaceNotFound
+}
+
+func (f *fakeWorkspaceRepo) IDs(_ context.Context) ([]string, error) {
+	out := []string{}
+	for _, w := range f.workspaces {
+		out = append(out, w.ID)
+	}
+	return out, nil
+}
+
+func (f *fakeWorkspaceRepo) Members(_ context.Context, workspaceID string) ([]models.WorkspaceUser, error) {
+	out := []models.WorkspaceUser{}
+	for _, m := range f.members[workspaceID] {
+		for email, id := range f.users {
+			if id == m.UserID {
+				m.Email = email
+			}
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func (f *fakeWorkspaceRepo) SetMember(_ context.Context, workspaceID, email string, role models.WorkspaceRole) (*models.WorkspaceUser, error) {
+	id, ok := f.users[email]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	m := models.WorkspaceUser{WorkspaceID: workspaceID, UserID: id, Email: email, Role: role}
+	for i, existing := range f.members[workspaceID] {
+		if existing.UserID == id {
+			f.members[workspaceID][i] = m
+			return &m, nil
+		}
+	}
+	f.members[workspaceID] = append(f.members[workspaceID], m)
+	return &m, nil
+}
+
+func (f *fakeWorkspaceRepo) RemoveMember(_ context.Context, workspaceID, userID string) error {
+	for i, m := range f.members[workspaceID] {
+		if m.UserID == userID {
+			f.members[workspaceID] = append(f.members[workspaceID][:i], f.members[workspaceID][i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrWorkspaceUserNotFound
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	svc := NewWorkspaceService(newFakeWorkspaceRepo())
+	alice := sessionCtx("alice")
+
+	w, err := svc.CreateWorkspace(alice, WorkspaceInput{Slug: " Acme ", Name: "Acme Inc"})
+	if err != nil {
+		t.Fatalf("create err: %v", err)
+	}
+	if w.Slug != "acme" || w.Role != models.WorkspaceAdmin {
+		t.Fatalf("unexpected workspace %+v", w)
+	}
+
+	if _, err := svc.CreateWorkspace(sessionCtx("bob"), WorkspaceInput{Slug: "acme", Name: "Other"}); !errors.Is(err, ErrSlugTaken) {
+		t.Fatalf("expected ErrSlugTaken, got %v", err)
+	}
+	for _, in := range []WorkspaceInput{
+		{Slug: "", Name: "x"},
+		{Slug: "-acme", Name: "x"},
+		{Slug: "ac me", Name: "x"},
+	} {
+		if _, err := svc.CreateWorkspace(alice, in); !errors.Is(err, ErrInvalidWorkspaceSlug) {
+			t.Errorf("%+v: expected ErrInvalidWorkspaceSlug, got %v", in, err)
+		}
+	}
+	if _, err := svc.CreateWorkspace(alice, WorkspaceInput{Slug: "blank", Name: " "}); !errors.Is(err, ErrInvalidWorkspaceName) {
+		t.Fatalf("expected ErrInvalidWorkspaceName, got %v", err)
+	}
+	if _, err := svc.CreateWorkspace(context.Background(), WorkspaceInput{Slug: "anon", Name: "Anon"}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	svc := NewWorkspaceService(newFakeWorkspaceRepo())
+	alice := sessionCtx("alice")
+
+	first, _ := svc.CreateWorkspace(alice, WorkspaceInput{Slug: "first", Name: "First"})
+	second, _ := svc.CreateWorkspace(alice, WorkspaceInput{Slug: "second", Name: "Second"})
+	svc.CreateWorkspace(sessionCtx("bob"), WorkspaceInput{Slug: "bobs", Name: "Bob's"})
+
+	if id, err := svc.ResolveWorkspace(alice, ""); err != nil || id != first.ID {
+		t.Fatalf("expected the first workspace, got %q, %v", id, err)
+	}
+	if id, err := svc.ResolveWorkspace(alice, "second"); err != nil || id != second.ID {
+		t.Fatalf("expected the second workspace, got %q, %v", id, err)
+	}
+	if _, err := svc.ResolveWorkspace(alice, "bobs"); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Fatalf("expected ErrWorkspaceNotFound for someone else's workspace, got %v", err)
+	}
+	if id, err := svc.ResolveWorkspace(context.Background(), "first"); err != nil || id != "" {
+		t.Fatalf("expected no workspace when anonymous, got %q, %v", id, err)
+	}
+	if id, err := svc.ResolveWorkspace(sessionCtx("carol"), ""); err != nil || id != "" {
+		t.Fatalf("expected no workspace for a user in none, got %q, %v", id, err)
+	}
+
+	keyCtx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "alice", APIKeyID: "k1", WorkspaceID: second.ID})
+	if id, err := svc.ResolveWorkspace(keyCtx, ""); err != nil || id != second.ID {
+		t.Fatalf("expected the key's workspace, got %q, %v", id, err)
+	}
+	if _, err := svc.ResolveWorkspace(keyCtx, "first"); !errors.Is(err, ErrWrongWorkspace) {
+		t.Fatalf("expected ErrWrongWorkspace, got %v", err)
+	}
+}
+
+func TestWorkspaceMembers(t *testing.T) {
+	svc := NewWorkspaceService(newFakeWorkspaceRepo())
+	alice, bob, carol := sessionCtx("alice"), sessionCtx("bob"), sessionCtx("carol")
+
+	svc.CreateWorkspace(alice, WorkspaceInput{Slug: "acme", Name: "Acme"})
+	if _, err := svc.SetMember(alice, "acme", WorkspaceMemberInput{Email: "bob@example.com", Role: models.WorkspaceMember}); err != nil {
+		t.Fatalf("set member err: %v", err)
+	}
+
+	if _, err := svc.ListMembers(carol, "acme"); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Fatalf("expected ErrWorkspaceNotFound for an outsider, got %v", err)
+	}
+	members, err := svc.ListMembers(bob, "acme")
+	if err != nil || len(members) != 2 {
+		t.Fatalf("expected 2 members, got %+v, %v", members, err)
+	}
+	if _, err := svc.SetMember(bob, "acme", WorkspaceMemberInput{Email: "carol@example.com", Role: models.WorkspaceMember}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a plain member, got %v", err)
+	}
+	if _, err := svc.SetMember(alice, "acme", WorkspaceMemberInput{Email: "nobody@example.com", Role: models.WorkspaceMember}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := svc.SetMember(alice, "acme", WorkspaceMemberInput{Email: "bob@example.com", Role: "owner"}); !errors.Is(err, ErrInvalidWorkspaceRole) {
+		t.Fatalf("expected ErrInvalidWorkspaceRole, got %v", err)
+	}
+
+	if _, err := svc.SetMember(alice, "acme", WorkspaceMemberInput{Email: "alice@example.com", Role: models.WorkspaceMember}); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("expected ErrLastAdmin demoting the only admin, got %v", err)
+	}
+	if err := svc.RemoveMember(alice, "acme", "alice"); !errors.Is(err, ErrLastAdmin) {
+		t.Fatalf("expected ErrLastAdmin when the only admin leaves, got %v", err)
+	}
+
+	// members may leave on their own
+	if err := svc.RemoveMember(bob, "acme", "bob"); err != nil {
+		t.Fatalf("leave err: %v", err)
+	}
+	if err := svc.RemoveMember(alice, "acme", "bob"); !errors.Is(err, ErrMemberNotFound) {
+		t.Fatalf("expected ErrMemberNotFound, got %v", err)
+	}
+}
+
+func TestWorkspaceEach(t *testing.T) {
+	svc := NewWorkspaceService(newFakeWorkspaceRepo())
+	svc.CreateWorkspace(sessionCtx("alice"), WorkspaceInput{Slug: "one", Name: "One"})
+	svc.CreateWorkspace(sessionCtx("alice"), WorkspaceInput{Slug: "two", Name: "Two"})
+
+	var seen []string
+	boom := errors.New("boom")
+	err := svc.Each(context.Background(), func(ctx context.Context) error {
+		seen = append(seen, tenant.WorkspaceID(ctx))
+		return boom
+	})
+	if len(seen) != 2 || seen[0] != "w1" || seen[1] != "w2" {
+		t.Fatalf("expected both workspaces to run, got %v", seen)
+	}
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the errors to be reported, got %v", err)
+	}
+}
+
+func TestCreateAPIKeyNeedsAWorkspace(t *testing.T) {
+	svc := NewAPIKeyService(&fakeAPIKeyRepo{})
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "u1", SessionID: "s-u1"})
+
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyInput{Name: "ci", Scopes: auth.Scopes}); !errors.Is(err, ErrNoWorkspace) {
+		t.Fatalf("expected ErrNoWorkspace, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+// Package tenant carries the workspace a request works in from the HTTP
+// layer down to the repositories, which only read and write rows of that
+// workspace.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoWorkspace is returned by repositories asked to touch workspace data
+// without a workspace in the context.
+var ErrNoWorkspace = errors.New("no workspace selected")
+
+type ctxKey int
+
+const workspaceKey ctxKey = iota
+
+func WithWorkspace(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, workspaceKey, id)
+}
+
+// WorkspaceID is the id of the current workspace, or empty when none was
+// selected.
+func WorkspaceID(ctx context.Context) string {
+	v, _ := ctx.Value(workspaceKey).(string)
+	return v
+}
+
+// Require is WorkspaceID for code that must not run without a workspace.
+func Require(ctx context.Context) (string, error) {
+	id := WorkspaceID(ctx)
+	if id == "" {
+		return "", ErrNoWorkspace
+	}
+	return id, nil
+}
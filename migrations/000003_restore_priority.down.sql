@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_priority_created_at;
+
+ALTER TABLE public.tasks
+DROP COLUMN IF EXISTS priority;
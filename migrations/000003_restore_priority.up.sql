@@ -0,0 +1,7 @@
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 1
+	CHECK (priority BETWEEN 1 AND 5);
+
+-- Helpful index for triage ordering
+CREATE INDEX IF NOT EXISTS idx_tasks_priority_created_at
+ON public.tasks (priority DESC, created_at DESC);
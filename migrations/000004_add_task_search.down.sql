@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE public.tasks
+DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- weighted full-text document: title ranks above description
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS search_vector tsvector
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'B')
+	) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector
+ON public.tasks USING GIN (search_vector);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_tags_tag_id;
+
+DROP TABLE IF EXISTS public.task_tags;
+DROP TABLE IF EXISTS public.tags;
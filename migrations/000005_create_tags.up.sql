@@ -0,0 +1,18 @@
+-- tags are stored lower-cased, so a plain UNIQUE is case-insensitive in practice
+CREATE TABLE IF NOT EXISTS public.tags (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	name TEXT NOT NULL UNIQUE
+		CHECK (char_length(name) BETWEEN 1 AND 50),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- many-to-many join between tasks and tags
+CREATE TABLE IF NOT EXISTS public.task_tags (
+	task_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	tag_id UUID NOT NULL REFERENCES public.tags (id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, tag_id)
+);
+
+-- reverse lookup for tag filters
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag_id
+ON public.task_tags (tag_id);
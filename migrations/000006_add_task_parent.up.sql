@@ -0,0 +1,9 @@
+-- optional parent for subtasks; the service decides what deleting a parent does,
+-- SET NULL is only the fallback for rows removed outside of it
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS parent_id UUID
+	REFERENCES public.tasks (id) ON DELETE SET NULL
+	CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id
+ON public.tasks (parent_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_task_dependencies_blocker_id;
+
+DROP TABLE IF EXISTS public.task_dependencies;
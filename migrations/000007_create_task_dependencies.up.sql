@@ -0,0 +1,12 @@
+-- task_id is blocked by blocker_id
+CREATE TABLE IF NOT EXISTS public.task_dependencies (
+	task_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	blocker_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (task_id, blocker_id),
+	CHECK (task_id <> blocker_id)
+);
+
+-- reverse lookup: what does this task block?
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker_id
+ON public.task_dependencies (blocker_id);
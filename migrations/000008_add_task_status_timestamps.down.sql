@@ -0,0 +1,3 @@
+ALTER TABLE public.tasks
+DROP COLUMN IF EXISTS completed_at,
+DROP COLUMN IF EXISTS started_at;
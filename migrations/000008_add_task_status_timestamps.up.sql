@@ -0,0 +1,11 @@
+-- recorded by the service as tasks move through the workflow
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- backfill what we can for rows that predate the workflow, without
+-- letting the trigger stamp them all as updated now
+ALTER TABLE public.tasks DISABLE TRIGGER trg_tasks_set_updated_at;
+UPDATE public.tasks SET completed_at = updated_at WHERE status = 'done' AND completed_at IS NULL;
+UPDATE public.tasks SET started_at = created_at WHERE status IN ('in_progress', 'done') AND started_at IS NULL;
+ALTER TABLE public.tasks ENABLE TRIGGER trg_tasks_set_updated_at;
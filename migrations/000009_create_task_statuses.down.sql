@@ -0,0 +1,16 @@
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_status_fkey;
+
+-- custom statuses can't survive the old CHECK, fold them into their category
+UPDATE public.tasks t
+SET status = CASE s.category
+	WHEN 'not_started' THEN 'todo'
+	WHEN 'active' THEN 'in_progress'
+	ELSE 'done'
+END
+FROM public.task_statuses s
+WHERE s.name = t.status AND t.status NOT IN ('todo', 'in_progress', 'done');
+
+ALTER TABLE public.tasks
+ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in_progress', 'done'));
+
+DROP TABLE IF EXISTS public.task_statuses;
@@ -0,0 +1,24 @@
+-- status catalog replacing the hard-coded CHECK on tasks.status
+CREATE TABLE IF NOT EXISTS public.task_statuses (
+	name TEXT PRIMARY KEY
+		CHECK (name ~ '^[a-z][a-z0-9_]{0,29}$'),
+	category TEXT NOT NULL
+		CHECK (category IN ('not_started', 'active', 'closed')),
+	position INTEGER NOT NULL DEFAULT 0,
+	color TEXT NOT NULL DEFAULT '#9ca3af'
+		CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO public.task_statuses (name, category, position, color) VALUES
+	('todo', 'not_started', 0, '#9ca3af'),
+	('in_progress', 'active', 1, '#3b82f6'),
+	('done', 'closed', 2, '#22c55e')
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+
+-- renames follow through to tasks; statuses in use can't be deleted
+ALTER TABLE public.tasks
+ADD CONSTRAINT tasks_status_fkey FOREIGN KEY (status)
+	REFERENCES public.task_statuses (name) ON UPDATE CASCADE ON DELETE RESTRICT;
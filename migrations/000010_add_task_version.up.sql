@@ -0,0 +1,3 @@
+-- bumped on every update; exposed as the task's ETag
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
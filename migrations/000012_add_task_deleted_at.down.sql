@@ -0,0 +1,6 @@
+-- trashed rows would reappear as live tasks, so drop them first
+DELETE FROM public.tasks WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+ALTER TABLE public.tasks
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+-- soft delete: trashed tasks keep their row until purged
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON public.tasks (deleted_at) WHERE deleted_at IS NOT NULL;
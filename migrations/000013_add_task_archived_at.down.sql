@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_archived_at_idx;
+ALTER TABLE public.tasks
+DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,5 @@
+-- archived tasks stay in place but are hidden from lists by default
+ALTER TABLE public.tasks
+ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS tasks_archived_at_idx ON public.tasks (archived_at) WHERE archived_at IS NOT NULL;
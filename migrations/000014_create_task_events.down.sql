@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_tasks_record_event ON public.tasks;
+DROP FUNCTION IF EXISTS record_task_event();
+DROP TABLE IF EXISTS public.task_events;
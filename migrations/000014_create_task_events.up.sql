@@ -0,0 +1,70 @@
+-- audit trail: one row per change to a task, written by a trigger in the
+-- same transaction as the change itself
+CREATE TABLE IF NOT EXISTS public.task_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- no foreign key, so the history outlives a purged task
+    task_id UUID NOT NULL,
+    action TEXT NOT NULL
+        CHECK (action IN ('created', 'updated', 'trashed', 'restored', 'archived', 'unarchived', 'purged')),
+    -- {"field": {"before": ..., "after": ...}}
+    changes JSONB NOT NULL DEFAULT '{}',
+    actor TEXT,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON public.task_events (task_id, id DESC);
+
+-- actor and request id come from transaction-local settings the
+-- repositories set before writing (taskapi.actor, taskapi.request_id)
+CREATE OR REPLACE FUNCTION record_task_event()
+RETURNS TRIGGER AS $$
+DECLARE
+	old_doc JSONB := '{}';
+	new_doc JSONB := '{}';
+	diff JSONB;
+	act TEXT;
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		old_doc := to_jsonb(OLD) - 'search_vector' - 'updated_at' - 'version';
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		new_doc := to_jsonb(NEW) - 'search_vector' - 'updated_at' - 'version';
+	END IF;
+
+	SELECT coalesce(jsonb_object_agg(k, jsonb_build_object('before', old_doc -> k, 'after', new_doc -> k)), '{}')
+	INTO diff
+	FROM jsonb_object_keys(old_doc || new_doc) AS k
+	WHERE coalesce(old_doc -> k, 'null') IS DISTINCT FROM coalesce(new_doc -> k, 'null');
+
+	IF TG_OP = 'INSERT' THEN
+		act := 'created';
+	ELSIF TG_OP = 'DELETE' THEN
+		act := 'purged';
+		diff := '{}';
+	ELSIF diff = '{}' THEN
+		RETURN NULL;
+	ELSIF diff ? 'deleted_at' THEN
+		act := CASE WHEN NEW.deleted_at IS NULL THEN 'restored' ELSE 'trashed' END;
+	ELSIF diff ? 'archived_at' THEN
+		act := CASE WHEN NEW.archived_at IS NULL THEN 'unarchived' ELSE 'archived' END;
+	ELSE
+		act := 'updated';
+	END IF;
+
+	INSERT INTO public.task_events (task_id, action, changes, actor, request_id)
+	VALUES (
+		CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END,
+		act,
+		diff,
+		nullif(current_setting('taskapi.actor', true), ''),
+		nullif(current_setting('taskapi.request_id', true), '')
+	);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_record_event ON public.tasks;
+CREATE TRIGGER trg_tasks_record_event
+AFTER INSERT OR UPDATE OR DELETE ON public.tasks
+FOR EACH ROW EXECUTE FUNCTION record_task_event();
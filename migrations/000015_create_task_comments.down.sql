@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_task_comments_task_id_created_at;
+DROP TRIGGER IF EXISTS trg_task_comments_set_updated_at ON public.task_comments;
+DROP TABLE IF EXISTS public.task_comments;
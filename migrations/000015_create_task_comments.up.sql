@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS public.task_comments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	task_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	-- the actor who wrote it; empty for anonymous requests
+	author TEXT NOT NULL DEFAULT '',
+	body TEXT NOT NULL
+		CHECK (char_length(body) BETWEEN 1 AND 10000),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_task_comments_set_updated_at ON public.task_comments;
+CREATE TRIGGER trg_task_comments_set_updated_at
+BEFORE UPDATE ON public.task_comments
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- threads are read oldest first
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_id_created_at
+ON public.task_comments (task_id, created_at, id);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS trg_task_attachments_queue_blob ON public.task_attachments;
+DROP FUNCTION IF EXISTS queue_orphaned_blob();
+DROP TABLE IF EXISTS public.orphaned_blobs;
+DROP INDEX IF EXISTS idx_task_attachments_task_id_created_at;
+DROP TABLE IF EXISTS public.task_attachments;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS public.task_attachments (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	task_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	filename TEXT NOT NULL
+		CHECK (char_length(filename) BETWEEN 1 AND 255),
+	content_type TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+	sha256 TEXT NOT NULL,
+	-- where the bytes live in the blob store
+	storage_key TEXT NOT NULL UNIQUE,
+	uploaded_by TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_attachments_task_id_created_at
+ON public.task_attachments (task_id, created_at, id);
+
+-- blobs whose metadata is gone, waiting to be removed from the store
+CREATE TABLE IF NOT EXISTS public.orphaned_blobs (
+	storage_key TEXT PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- fires for cascades from purged tasks too
+CREATE OR REPLACE FUNCTION queue_orphaned_blob()
+RETURNS TRIGGER AS $$
+BEGIN
+	INSERT INTO public.orphaned_blobs (storage_key) VALUES (OLD.storage_key)
+	ON CONFLICT DO NOTHING;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_task_attachments_queue_blob ON public.task_attachments;
+CREATE TRIGGER trg_task_attachments_queue_blob
+AFTER DELETE ON public.task_attachments
+FOR EACH ROW EXECUTE FUNCTION queue_orphaned_blob();
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_tasks_created_by;
+ALTER TABLE public.tasks DROP COLUMN IF EXISTS created_by;
+
+DROP INDEX IF EXISTS idx_password_reset_tokens_user_id;
+DROP TABLE IF EXISTS public.password_reset_tokens;
+
+DROP TRIGGER IF EXISTS trg_users_set_updated_at ON public.users;
+DROP TABLE IF EXISTS public.users;
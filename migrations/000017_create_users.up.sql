@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS public.users (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	-- stored lower-cased, so UNIQUE is case-insensitive in practice
+	email TEXT NOT NULL UNIQUE
+		CHECK (char_length(email) BETWEEN 3 AND 254),
+	name TEXT NOT NULL DEFAULT '',
+	-- PHC string; argon2id, or bcrypt for imported accounts
+	password_hash TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DROP TRIGGER IF EXISTS trg_users_set_updated_at ON public.users;
+CREATE TRIGGER trg_users_set_updated_at
+BEFORE UPDATE ON public.users
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- only a hash of each token is kept; the token itself is sent to the user
+CREATE TABLE IF NOT EXISTS public.password_reset_tokens (
+	token_hash TEXT PRIMARY KEY,
+	user_id UUID NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id
+ON public.password_reset_tokens (user_id);
+
+-- tasks created before accounts existed have no owner
+ALTER TABLE public.tasks
+	ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES public.users (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_created_by
+ON public.tasks (created_by);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_session_id;
+DROP TABLE IF EXISTS public.refresh_tokens;
+
+DROP INDEX IF EXISTS idx_auth_sessions_user_id;
+DROP TABLE IF EXISTS public.auth_sessions;
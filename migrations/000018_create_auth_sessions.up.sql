@@ -0,0 +1,24 @@
+-- one row per login; access tokens carry the session id, so revoking the
+-- session cuts them off too
+CREATE TABLE IF NOT EXISTS public.auth_sessions (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	-- pushed forward each time the refresh token is rotated
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_sessions_user_id
+ON public.auth_sessions (user_id);
+
+-- used tokens are kept so that replaying one can be spotted
+CREATE TABLE IF NOT EXISTS public.refresh_tokens (
+	token_hash TEXT PRIMARY KEY,
+	session_id UUID NOT NULL REFERENCES public.auth_sessions (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id
+ON public.refresh_tokens (session_id);
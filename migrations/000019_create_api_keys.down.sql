@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+DROP TABLE IF EXISTS public.api_keys;
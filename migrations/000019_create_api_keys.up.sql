@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS public.api_keys (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	user_id UUID NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	name TEXT NOT NULL
+		CHECK (char_length(name) BETWEEN 1 AND 100),
+	-- the first characters of the key, so users can tell their keys apart
+	prefix TEXT NOT NULL,
+	-- keys are long and random, so a plain SHA-256 is enough
+	key_hash TEXT NOT NULL UNIQUE,
+	-- space-separated, like an OAuth scope parameter
+	scopes TEXT NOT NULL,
+	expires_at TIMESTAMPTZ,
+	last_used_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id
+ON public.api_keys (user_id);
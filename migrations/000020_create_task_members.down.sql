@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS trg_tasks_add_owner ON public.tasks;
+DROP FUNCTION IF EXISTS add_task_owner();
+DROP FUNCTION IF EXISTS task_role(UUID, UUID);
+
+DROP INDEX IF EXISTS idx_task_members_user_id;
+DROP TABLE IF EXISTS public.task_members;
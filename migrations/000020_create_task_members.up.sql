@@ -0,0 +1,51 @@
+-- who may do what with a task; a role on a task also covers its subtasks
+CREATE TABLE IF NOT EXISTS public.task_members (
+	task_id UUID NOT NULL REFERENCES public.tasks (id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	role TEXT NOT NULL
+		CHECK (role IN ('owner', 'editor', 'viewer')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_members_user_id
+ON public.task_members (user_id);
+
+-- the strongest role usr holds on the task or any of its ancestors, or
+-- NULL; trashed tasks count too, so they can be restored
+CREATE OR REPLACE FUNCTION task_role(task UUID, usr UUID)
+RETURNS TEXT AS $$
+	WITH RECURSIVE up AS (
+		SELECT id, parent_id, 1 AS depth FROM public.tasks WHERE id = task
+		UNION ALL
+		SELECT t.id, t.parent_id, up.depth + 1
+		FROM public.tasks t JOIN up ON t.id = up.parent_id
+		WHERE up.depth < 50
+	)
+	SELECT m.role
+	FROM up JOIN public.task_members m ON m.task_id = up.id AND m.user_id = usr
+	ORDER BY CASE m.role WHEN 'owner' THEN 3 WHEN 'editor' THEN 2 ELSE 1 END DESC
+	LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+-- whoever creates a task owns it
+CREATE OR REPLACE FUNCTION add_task_owner()
+RETURNS TRIGGER AS $$
+BEGIN
+	IF NEW.created_by IS NOT NULL THEN
+		INSERT INTO public.task_members (task_id, user_id, role)
+		VALUES (NEW.id, NEW.created_by, 'owner')
+		ON CONFLICT DO NOTHING;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_add_owner ON public.tasks;
+CREATE TRIGGER trg_tasks_add_owner
+AFTER INSERT ON public.tasks
+FOR EACH ROW EXECUTE FUNCTION add_task_owner();
+
+INSERT INTO public.task_members (task_id, user_id, role)
+SELECT id, created_by, 'owner' FROM public.tasks WHERE created_by IS NOT NULL
+ON CONFLICT DO NOTHING;
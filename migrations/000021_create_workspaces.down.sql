@@ -0,0 +1,54 @@
+DROP POLICY IF EXISTS task_events_workspace_isolation ON public.task_events;
+ALTER TABLE public.task_events NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.task_events DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS task_comments_workspace_isolation ON public.task_comments;
+ALTER TABLE public.task_comments NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.task_comments DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tasks_workspace_isolation ON public.tasks;
+ALTER TABLE public.tasks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE public.tasks DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE public.task_events DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE public.task_comments DROP CONSTRAINT IF EXISTS task_comments_task_id_fkey;
+ALTER TABLE public.task_comments DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE public.task_comments
+ADD CONSTRAINT task_comments_task_id_fkey FOREIGN KEY (task_id)
+	REFERENCES public.tasks (id) ON DELETE CASCADE;
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_workspace_id_id_key;
+
+DROP FUNCTION IF EXISTS current_workspace();
+
+ALTER TABLE public.api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP TRIGGER IF EXISTS trg_workspaces_seed_statuses ON public.workspaces;
+DROP FUNCTION IF EXISTS seed_workspace_statuses();
+
+-- folds the catalogs into one, keeping the default workspace's version of
+-- each name, or else the one of the lowest workspace id
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_status_fkey;
+DELETE FROM public.task_statuses s
+USING public.task_statuses k
+WHERE k.name = s.name AND k.workspace_id <> s.workspace_id
+	AND (k.workspace_id = '00000000-0000-0000-0000-000000000001'
+		OR (s.workspace_id <> '00000000-0000-0000-0000-000000000001' AND k.workspace_id < s.workspace_id));
+ALTER TABLE public.task_statuses DROP CONSTRAINT IF EXISTS task_statuses_pkey;
+ALTER TABLE public.task_statuses ADD CONSTRAINT task_statuses_pkey PRIMARY KEY (name);
+ALTER TABLE public.task_statuses DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE public.tasks
+ADD CONSTRAINT tasks_status_fkey FOREIGN KEY (status)
+	REFERENCES public.task_statuses (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- fails if two workspaces used the same tag name
+ALTER TABLE public.tags DROP CONSTRAINT IF EXISTS tags_workspace_id_name_key;
+ALTER TABLE public.tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+ALTER TABLE public.tags DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_tasks_workspace_id;
+ALTER TABLE public.tasks DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+DROP TABLE IF EXISTS public.workspace_members;
+DROP TABLE IF EXISTS public.workspaces;
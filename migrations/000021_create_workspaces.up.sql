@@ -0,0 +1,155 @@
+-- a workspace is one team's corner of the deployment; tasks and tags
+-- belong to exactly one
+CREATE TABLE IF NOT EXISTS public.workspaces (
+	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+	-- used in the X-Workspace header and as a subdomain
+	slug TEXT NOT NULL UNIQUE
+		CHECK (slug ~ '^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$'),
+	name TEXT NOT NULL
+		CHECK (char_length(name) BETWEEN 1 AND 100),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.workspace_members (
+	workspace_id UUID NOT NULL REFERENCES public.workspaces (id) ON DELETE CASCADE,
+	user_id UUID NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+	role TEXT NOT NULL
+		CHECK (role IN ('admin', 'member')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id
+ON public.workspace_members (user_id);
+
+-- everything that exists already moves into one workspace, which every
+-- existing user administers
+INSERT INTO public.workspaces (id, slug, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO public.workspace_members (workspace_id, user_id, role)
+SELECT '00000000-0000-0000-0000-000000000001', id, 'admin' FROM public.users
+ON CONFLICT DO NOTHING;
+
+-- a constant default fills existing rows without firing update triggers
+ALTER TABLE public.tasks
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001'
+	REFERENCES public.workspaces (id) ON DELETE CASCADE;
+ALTER TABLE public.tasks ALTER COLUMN workspace_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_id
+ON public.tasks (workspace_id, created_at, id);
+
+ALTER TABLE public.tags
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001'
+	REFERENCES public.workspaces (id) ON DELETE CASCADE;
+ALTER TABLE public.tags ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE public.tags DROP CONSTRAINT IF EXISTS tags_name_key;
+ALTER TABLE public.tags ADD CONSTRAINT tags_workspace_id_name_key UNIQUE (workspace_id, name);
+
+-- each workspace keeps its own status catalog. Tasks reference statuses of
+-- their own workspace, so a rename only cascades to that workspace's tasks.
+ALTER TABLE public.task_statuses
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001'
+	REFERENCES public.workspaces (id) ON DELETE CASCADE;
+ALTER TABLE public.task_statuses ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE public.tasks DROP CONSTRAINT IF EXISTS tasks_status_fkey;
+ALTER TABLE public.task_statuses DROP CONSTRAINT IF EXISTS task_statuses_pkey;
+ALTER TABLE public.task_statuses ADD CONSTRAINT task_statuses_pkey PRIMARY KEY (workspace_id, name);
+
+-- NO ACTION rather than RESTRICT, so deleting a workspace can take its
+-- tasks and statuses with it in one statement
+ALTER TABLE public.tasks
+ADD CONSTRAINT tasks_status_fkey FOREIGN KEY (workspace_id, status)
+	REFERENCES public.task_statuses (workspace_id, name) ON UPDATE CASCADE ON DELETE NO ACTION;
+
+-- new workspaces start with the built-in statuses
+CREATE OR REPLACE FUNCTION seed_workspace_statuses()
+RETURNS TRIGGER AS $$
+BEGIN
+	INSERT INTO public.task_statuses (workspace_id, name, category, position, color) VALUES
+		(NEW.id, 'todo', 'not_started', 0, '#9ca3af'),
+		(NEW.id, 'in_progress', 'active', 1, '#3b82f6'),
+		(NEW.id, 'done', 'closed', 2, '#22c55e')
+	ON CONFLICT DO NOTHING;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_workspaces_seed_statuses ON public.workspaces;
+CREATE TRIGGER trg_workspaces_seed_statuses
+AFTER INSERT ON public.workspaces
+FOR EACH ROW EXECUTE FUNCTION seed_workspace_statuses();
+
+-- an API key only works in the workspace it was created in
+ALTER TABLE public.api_keys
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001'
+	REFERENCES public.workspaces (id) ON DELETE CASCADE;
+ALTER TABLE public.api_keys ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- the workspace the current transaction works in, which the repositories
+-- set before touching tasks (taskapi.workspace_id); NULL when unset
+CREATE OR REPLACE FUNCTION current_workspace()
+RETURNS UUID AS $$
+	SELECT nullif(current_setting('taskapi.workspace_id', true), '')::uuid;
+$$ LANGUAGE sql STABLE;
+
+-- comments and history carry user content, so they get a workspace_id of
+-- their own. A comment's is its task's, which the foreign key enforces.
+ALTER TABLE public.tasks ADD CONSTRAINT tasks_workspace_id_id_key UNIQUE (workspace_id, id);
+
+ALTER TABLE public.task_comments
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE public.task_comments ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE public.task_comments DROP CONSTRAINT IF EXISTS task_comments_task_id_fkey;
+ALTER TABLE public.task_comments
+ADD CONSTRAINT task_comments_task_id_fkey FOREIGN KEY (workspace_id, task_id)
+	REFERENCES public.tasks (workspace_id, id) ON DELETE CASCADE;
+
+-- events outlive their task, so they can't reference it. The trigger
+-- writes them in the stamped transaction of the task change, whose
+-- workspace is the task's.
+ALTER TABLE public.task_events
+	ADD COLUMN IF NOT EXISTS workspace_id UUID NOT NULL
+	DEFAULT '00000000-0000-0000-0000-000000000001'
+	REFERENCES public.workspaces (id) ON DELETE CASCADE;
+ALTER TABLE public.task_events ALTER COLUMN workspace_id SET DEFAULT current_workspace();
+
+-- row-level security keeps every query on tasks, comments and history
+-- inside the current workspace, even one that forgets to filter. It
+-- doesn't bind superusers or roles with BYPASSRLS, so the API should
+-- connect as neither.
+--
+-- The other tables hanging off a task (task_attachments, task_members,
+-- task_dependencies and task_tags) have no policy yet. They are reached
+-- through a task id the API has first looked up in tasks, but a query on
+-- them alone is not held to the workspace.
+ALTER TABLE public.tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.tasks FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tasks_workspace_isolation ON public.tasks;
+CREATE POLICY tasks_workspace_isolation ON public.tasks
+	USING (workspace_id = current_workspace())
+	WITH CHECK (workspace_id = current_workspace());
+
+ALTER TABLE public.task_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.task_comments FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS task_comments_workspace_isolation ON public.task_comments;
+CREATE POLICY task_comments_workspace_isolation ON public.task_comments
+	USING (workspace_id = current_workspace())
+	WITH CHECK (workspace_id = current_workspace());
+
+ALTER TABLE public.task_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE public.task_events FORCE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS task_events_workspace_isolation ON public.task_events;
+CREATE POLICY task_events_workspace_isolation ON public.task_events
+	USING (workspace_id = current_workspace())
+	WITH CHECK (workspace_id = current_workspace());
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// APIKey is a long-lived credential for scripts. The key itself is only
+// shown when it is created.
+type APIKey struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	// WorkspaceID is the only workspace the key works in
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
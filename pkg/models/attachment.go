@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Attachment is the metadata of a file attached to a task; the bytes live
+// in a blob store under StorageKey.
+type Attachment struct {
+	ID          string    `db:"id" json:"id"`
+	TaskID      string    `db:"task_id" json:"task_id"`
+	Filename    string    `db:"filename" json:"filename"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	Size        int64     `db:"size_bytes" json:"size"`
+	SHA256      string    `db:"sha256" json:"sha256"`
+	StorageKey  string    `db:"storage_key" json:"-"`
+	UploadedBy  string    `db:"uploaded_by" json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrBlobNotFound       = errors.New("blob not found")
+)
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type Comment struct {
+	ID        string    `db:"id" json:"id"`
+	TaskID    string    `db:"task_id" json:"task_id"`
+	Author    string    `db:"author" json:"author,omitempty"`
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+var ErrCommentNotFound = errors.New("comment not found")
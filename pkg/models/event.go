@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventAction string
+
+const (
+	EventCreated    EventAction = "created"
+	EventUpdated    EventAction = "updated"
+	EventTrashed    EventAction = "trashed"
+	EventRestored   EventAction = "restored"
+	EventArchived   EventAction = "archived"
+	EventUnarchived EventAction = "unarchived"
+	EventPurged     EventAction = "purged"
+)
+
+// FieldChange holds a column's JSON value before and after a change; a
+// side is null when the task did not exist on it.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// TaskEvent is one entry of a task's audit trail.
+type TaskEvent struct {
+	ID        int64                  `json:"id"`
+	TaskID    string                 `json:"task_id"`
+	Action    EventAction            `json:"action"`
+	Changes   map[string]FieldChange `json:"changes"`
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Role is what a user may do with a task and its subtasks. Each role
+// includes the ones below it: owner > editor > viewer.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+// roles is ordered weakest first
+var roles = []Role{RoleViewer, RoleEditor, RoleOwner}
+
+func (r Role) rank() int {
+	for i, role := range roles {
+		if role == r {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (r Role) Valid() bool {
+	return r.rank() > 0
+}
+
+// Includes reports whether r grants everything need does.
+func (r Role) Includes(need Role) bool {
+	return r.Valid() && r.rank() >= need.rank()
+}
+
+// RolesIncluding lists the roles that include need, for SQL filters.
+func RolesIncluding(need Role) []string {
+	var out []string
+	for _, role := range roles {
+		if role.Includes(need) {
+			out = append(out, string(role))
+		}
+	}
+	return out
+}
+
+// TaskMember is a role granted directly on a task.
+type TaskMember struct {
+	TaskID    string    `db:"task_id" json:"task_id"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Email     string    `db:"email" json:"email"`
+	Name      string    `db:"name" json:"name"`
+	Role      Role      `db:"role" json:"role"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+var ErrMemberNotFound = errors.New("member not found")
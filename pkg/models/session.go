@@ -0,0 +1,22 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Session is one login. It lives as long as its refresh token keeps being
+// rotated, or until it is revoked.
+type Session struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    string     `db:"user_id" json:"user_id"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used")
+)
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// StatusCategory groups statuses by what they mean for a task's lifecycle.
+type StatusCategory string
+
+const (
+	CategoryNotStarted StatusCategory = "not_started"
+	CategoryActive     StatusCategory = "active"
+	CategoryClosed     StatusCategory = "closed"
+)
+
+func (c StatusCategory) Valid() bool {
+	switch c {
+	case CategoryNotStarted, CategoryActive, CategoryClosed:
+		return true
+	}
+	return false
+}
+
+// StatusDefinition is one entry of the status catalog.
+type StatusDefinition struct {
+	Name      TaskStatus     `db:"name" json:"name"`
+	Category  StatusCategory `db:"category" json:"category"`
+	Position  int            `db:"position" json:"position"`
+	Color     string         `db:"color" json:"color"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrStatusNotFound = errors.New("status not found")
+	ErrStatusInUse    = errors.New("status is in use")
+)
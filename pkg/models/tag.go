@@ -0,0 +1,17 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type Tag struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrConflict    = errors.New("already exists")
+)
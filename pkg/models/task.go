@@ -8,36 +8,79 @@ import (
 
 type TaskStatus string
 
+// Built-in statuses seeded into the status catalog
 const (
 	StatusTodo       TaskStatus = "todo"
 	StatusInProgress TaskStatus = "in_progress"
 	StatusDone       TaskStatus = "done"
 )
 
+// Priority bounds match the CHECK constraint on public.tasks.priority
+const (
+	PriorityMin     = 1
+	PriorityMax     = 5
+	PriorityDefault = PriorityMin
+)
+
 type Task struct {
 	ID          string     `db:"id" json:"id"`
+	ParentID    *string    `db:"parent_id" json:"parent_id"`
 	Title       string     `db:"title" json:"title"`
 	Description string     `db:"description" json:"description"`
 	Status      TaskStatus `db:"status" json:"status"`
+	Priority    int        `db:"priority" json:"priority"`
 	DueAt       *time.Time `db:"due_at" json:"due_at"`
+	StartedAt   *time.Time `db:"started_at" json:"started_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	Version     int        `db:"version" json:"version"`
+	ArchivedAt  *time.Time `db:"archived_at" json:"archived_at"`
+	// CreatedBy is the id of the user who created the task, if any
+	CreatedBy *string `db:"created_by" json:"created_by"`
+	// DeletedAt is set while the task sits in the trash
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	Tags      []string   `db:"-" json:"tags"`
+	Progress  *Progress  `db:"-" json:"progress,omitempty"`
+	// Blocked is true while any live blocker is not closed
+	Blocked      bool `db:"-" json:"blocked"`
+	CommentCount int  `db:"-" json:"comment_count"`
+
+	// Only populated on search results
+	Rank    float64 `db:"rank" json:"rank,omitempty"`
+	Snippet string  `db:"snippet" json:"snippet,omitempty"`
+}
+
+// Progress rolls up a parent's direct children; nil when it has none
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 // Errors to be used everywhere
 var (
-	ErrNotFound   = errors.New("task not found")
-	ErrValidation = errors.New("validation error")
+	ErrNotFound           = errors.New("task not found")
+	ErrValidation         = errors.New("validation error")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	// ErrDependencyGraphTooLarge means a cycle check gave up before finishing
+	ErrDependencyGraphTooLarge = errors.New("dependency graph is too large to check for cycles")
+	ErrVersionConflict         = errors.New("task was modified concurrently")
 )
 
 func (t *Task) Validate() error {
 	if len(t.Title) == 0 {
 		return fmt.Errorf("%w: title is required", ErrValidation)
 	}
-	switch t.Status {
-	case StatusTodo, StatusInProgress, StatusDone:
-	default:
-		return fmt.Errorf("%w: invalid status %q", ErrValidation, t.Status)
+	// The status catalog lives in the database; here we only require one
+	if t.Status == "" {
+		return fmt.Errorf("%w: status is required", ErrValidation)
+	}
+	if t.ParentID != nil && *t.ParentID == t.ID {
+		return fmt.Errorf("%w: task cannot be its own parent", ErrValidation)
+	}
+	if t.Priority < PriorityMin || t.Priority > PriorityMax {
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrValidation, PriorityMin, PriorityMax)
 	}
 	return nil
 }
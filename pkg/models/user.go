@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type User struct {
+	ID           string    `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	Name         string    `db:"name" json:"name"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrResetTokenInvalid = errors.New("reset token is invalid or expired")
+)
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// WorkspaceRole is what a user may do in a workspace. Admins manage its
+// members; everything else is the same for both roles.
+type WorkspaceRole string
+
+const (
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceMember WorkspaceRole = "member"
+)
+
+func (r WorkspaceRole) Valid() bool {
+	return r == WorkspaceAdmin || r == WorkspaceMember
+}
+
+// Workspace is one team's share of the deployment. Its tasks and tags are
+// invisible from every other workspace.
+type Workspace struct {
+	ID        string    `db:"id" json:"id"`
+	Slug      string    `db:"slug" json:"slug"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Role is the current user's role in the workspace
+	Role WorkspaceRole `db:"role" json:"role"`
+}
+
+type WorkspaceUser struct {
+	WorkspaceID string        `db:"workspace_id" json:"workspace_id"`
+	UserID      string        `db:"user_id" json:"user_id"`
+	Email       string        `db:"email" json:"email"`
+	Name        string        `db:"name" json:"name"`
+	Role        WorkspaceRole `db:"role" json:"role"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+}
+
+var (
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrWorkspaceUserNotFound = errors.New("workspace member not found")
+)